@@ -0,0 +1,218 @@
+package main
+
+import (
+	"log"
+	"math"
+	"math/bits"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Achievement is unlocked once when Check holds during a game,
+// unlocking the skin or the BGM variant it names.
+type Achievement struct {
+	ID    string
+	Name  string
+	Skin  string
+	BGM   string
+	Check func(g *Game) bool
+}
+
+var Achievements []Achievement = []Achievement{
+	{"chain3", "3-chain", "Pastel", "", func(g *Game) bool {
+		return g.SequentErase >= 3
+	}},
+	{"chain5", "5-chain", "", "Calm", func(g *Game) bool {
+		return g.SequentErase >= 5
+	}},
+	{"jammers10", "10 jammers in a game", "Night", "", func(g *Game) bool {
+		return g.Stats.JammersCleared >= 10
+	}},
+	{"survive72", "turn 72 in six colors", "", "Drive", func(g *Game) bool {
+		return g.Turn > 72 && bits.OnesCount(g.Dealt) == 6
+	}},
+	{"fourway", "four ways at once", "Mint", "", func(g *Game) bool {
+		return g.Match.Directions() == 4
+	}},
+	{"allclear", "clear the board", "Gold", "", func(g *Game) bool {
+		return g.Match.Num() > 0 && g.Board.Remaining() == 0
+	}},
+}
+
+const AchievementKey = "achievements"
+
+func LoadUnlocked() map[string]bool {
+	var ids []string
+	if err := LoadData(AchievementKey, &ids); err != nil {
+		log.Println(err)
+	}
+	unlocked := map[string]bool{}
+	for _, id := range ids {
+		unlocked[id] = true
+	}
+	return unlocked
+}
+
+func SaveUnlocked(unlocked map[string]bool) {
+	var ids []string
+	for _, a := range Achievements {
+		if unlocked[a.ID] {
+			ids = append(ids, a.ID)
+		}
+	}
+	if err := SaveData(AchievementKey, ids); err != nil {
+		log.Println(err)
+	}
+}
+
+// AchievementHandler checks achievements after erase steps and turns of real games.
+func AchievementHandler(g *Game, e Event) {
+	if !g.Real() {
+		return
+	}
+	switch e.(type) {
+	case StonesMatched, TurnEnded:
+		g.CheckAchievements()
+	}
+}
+
+// CheckAchievements unlocks what the game reached and toasts the first new one.
+func (g *Game) CheckAchievements() {
+	var unlocked []Achievement
+	for _, a := range Achievements {
+		if !g.Unlocked[a.ID] && a.Check(g) {
+			g.Unlocked[a.ID] = true
+			unlocked = append(unlocked, a)
+		}
+	}
+	if len(unlocked) == 0 {
+		return
+	}
+	SaveUnlocked(g.Unlocked)
+	g.Toast = Tf("UNLOCKED %s", T(unlocked[0].Name))
+	g.ToastWait = ToastFrame
+}
+
+// Skin recolors stones.
+type Skin struct {
+	Name  string
+	Color func(m *ebiten.ColorM)
+}
+
+var Skins []Skin = []Skin{
+	{"Classic", func(m *ebiten.ColorM) {}},
+	{"Pastel", func(m *ebiten.ColorM) {
+		m.Scale(0.6, 0.6, 0.6, 1)
+		m.Translate(0.4, 0.4, 0.4, 0)
+	}},
+	{"Night", func(m *ebiten.ColorM) {
+		m.Scale(0.55, 0.55, 0.75, 1)
+	}},
+	{"Mint", func(m *ebiten.ColorM) {
+		m.ChangeHSV(math.Pi/3, 0.8, 1)
+	}},
+	{"Gold", func(m *ebiten.ColorM) {
+		m.ChangeHSV(0, 1.2, 1)
+		m.Scale(1, 0.9, 0.6, 1)
+	}},
+}
+
+// BGM variants choose which track plays during a game.
+const (
+	BGMStandard = "Standard"
+	// the calm track all the time
+	BGMCalm = "Calm"
+	// the game track all the time
+	BGMDrive = "Drive"
+)
+
+var BGMs []string = []string{BGMStandard, BGMCalm, BGMDrive}
+
+// Cosmetics are the chosen skin and BGM variant.
+type Cosmetics struct {
+	Skin string `json:"skin"`
+	BGM  string `json:"bgm"`
+}
+
+const CosmeticsKey = "cosmetics"
+
+var Cosmetic Cosmetics = Cosmetics{Skin: "Classic", BGM: BGMStandard}
+
+func LoadCosmetics() Cosmetics {
+	c := Cosmetics{Skin: "Classic", BGM: BGMStandard}
+	if err := LoadData(CosmeticsKey, &c); err != nil {
+		log.Println(err)
+	}
+	return c
+}
+
+func SaveCosmetics(c Cosmetics) {
+	if err := SaveData(CosmeticsKey, c); err != nil {
+		log.Println(err)
+	}
+}
+
+// ApplySkin recolors a stone drawn with opt.
+func ApplySkin(opt *ebiten.DrawImageOptions) {
+	for _, s := range Skins {
+		if s.Name == Cosmetic.Skin {
+			s.Color(&opt.ColorM)
+		}
+	}
+}
+
+// UnlockedSkins are Classic and the skins of unlocked achievements.
+func UnlockedSkins(unlocked map[string]bool) []string {
+	names := []string{"Classic"}
+	for _, a := range Achievements {
+		if a.Skin != "" && unlocked[a.ID] {
+			names = append(names, a.Skin)
+		}
+	}
+	return names
+}
+
+func UnlockedBGMs(unlocked map[string]bool) []string {
+	names := []string{BGMStandard}
+	for _, a := range Achievements {
+		if a.BGM != "" && unlocked[a.ID] {
+			names = append(names, a.BGM)
+		}
+	}
+	return names
+}
+
+// nextName returns the name after cur in names, going round.
+func nextName(names []string, cur string) string {
+	for i, n := range names {
+		if n == cur {
+			return names[(i+1)%len(names)]
+		}
+	}
+	return names[0]
+}
+
+func AchievementMenu(g *Game) *Menu {
+	m := &Menu{Title: T("Achievements")}
+	for _, a := range Achievements {
+		mark := "[ ]"
+		if g.Unlocked[a.ID] {
+			mark = "[x]"
+		}
+		m.Items = append(m.Items, MenuItem{mark + " " + T(a.Name), nil})
+	}
+	m.Items = append(m.Items,
+		MenuItem{Tf("Skin: %s", Cosmetic.Skin), func(g *Game) {
+			Cosmetic.Skin = nextName(UnlockedSkins(g.Unlocked), Cosmetic.Skin)
+			SaveCosmetics(Cosmetic)
+			g.OpenMenu(AchievementMenu(g))
+		}},
+		MenuItem{Tf("BGM: %s", Cosmetic.BGM), func(g *Game) {
+			Cosmetic.BGM = nextName(UnlockedBGMs(g.Unlocked), Cosmetic.BGM)
+			SaveCosmetics(Cosmetic)
+			g.OpenMenu(AchievementMenu(g))
+		}},
+		MenuItem{T("Back"), func(g *Game) { g.OpenMenu(MainMenu()) }},
+	)
+	return m
+}
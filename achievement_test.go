@@ -0,0 +1,81 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheckAchievements(t *testing.T) {
+	defer func(s Storage) { DefaultStorage = s }(DefaultStorage)
+
+	type Case struct {
+		t   string
+		set func(g *Game)
+		ids []string
+	}
+	cases := []Case{
+		Case{"nothing", func(g *Game) {}, nil},
+		Case{"chain 3", func(g *Game) { g.SequentErase = 3 }, []string{"chain3"}},
+		Case{"chain 5", func(g *Game) { g.SequentErase = 5 }, []string{"chain3", "chain5"}},
+		Case{"jammers", func(g *Game) { g.Stats.JammersCleared = 10 }, []string{"jammers10"}},
+		Case{"turn 72 in five colors", func(g *Game) {
+			g.Turn = 73
+			g.Dealt = 1<<Red | 1<<Blue | 1<<Green | 1<<Yellow | 1<<Pink
+		}, nil},
+		Case{"turn 72 in six colors", func(g *Game) {
+			g.Turn = 73
+			g.Dealt = 1<<Red | 1<<Blue | 1<<Green | 1<<Yellow | 1<<Pink | 1<<Orange
+		}, []string{"survive72"}},
+		Case{"four ways", func(g *Game) {
+			g.Match = MatchResult{Groups: []MatchGroup{
+				{Direction: Horizontal}, {Direction: Vertical}, {Direction: RightDown}, {Direction: RightUp},
+			}}
+		}, []string{"fourway"}},
+		Case{"all clear", func(g *Game) {
+			g.Match = MatchResult{Groups: []MatchGroup{{Cells: []Point{{1, 14}}}}}
+			g.Board.Cell[1][14].Erased = true
+		}, []string{"allclear"}},
+	}
+	for _, cs := range cases {
+		DefaultStorage = MemoryStorage{}
+		g := NewGame()
+		g.Start(Endless{})
+		g.Board.Cell[1][14] = &Stone{Color: Red}
+		cs.set(g)
+		g.CheckAchievements()
+		want := map[string]bool{}
+		for _, id := range cs.ids {
+			want[id] = true
+		}
+		if got := LoadUnlocked(); !reflect.DeepEqual(got, want) {
+			t.Error(cs.t, "unlocked", got, want)
+		}
+		if len(cs.ids) > 0 && g.ToastWait == 0 {
+			t.Error(cs.t, "no toast")
+		}
+	}
+}
+
+func TestUnlockedCosmetics(t *testing.T) {
+	unlocked := map[string]bool{"chain3": true, "chain5": true, "allclear": true}
+	if skins := UnlockedSkins(unlocked); !reflect.DeepEqual(skins, []string{"Classic", "Pastel", "Gold"}) {
+		t.Error("skins", skins)
+	}
+	if bgms := UnlockedBGMs(unlocked); !reflect.DeepEqual(bgms, []string{BGMStandard, BGMCalm}) {
+		t.Error("bgms", bgms)
+	}
+	type Case struct {
+		t         string
+		cur, next string
+	}
+	cases := []Case{
+		Case{"next", "Classic", "Pastel"},
+		Case{"round", "Gold", "Classic"},
+		Case{"locked", "Night", "Classic"},
+	}
+	for _, cs := range cases {
+		if n := nextName(UnlockedSkins(unlocked), cs.cur); n != cs.next {
+			t.Error(cs.t, n, cs.next)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"image/png"
+	"io"
+	"io/fs"
+	"log"
+
+	"github.com/hajimehoshi/bitmapfont/v2"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/audio"
+	"github.com/hajimehoshi/ebiten/v2/audio/vorbis"
+)
+
+// LoadStep is a part of the assets, loaded in a frame of its own.
+type LoadStep struct {
+	Name string
+	Load func() error
+	// the game goes on without optional steps, only logging their errors
+	Optional bool
+}
+
+// Loader runs the steps one by one, so that the loading screen is drawn between them.
+type Loader struct {
+	Steps []LoadStep
+	Done  int
+}
+
+func NewLoader(steps []LoadStep) *Loader {
+	return &Loader{Steps: steps}
+}
+
+func (l *Loader) Finished() bool {
+	return l.Done >= len(l.Steps)
+}
+
+func (l *Loader) Progress() float64 {
+	if len(l.Steps) == 0 {
+		return 1
+	}
+	return float64(l.Done) / float64(len(l.Steps))
+}
+
+// Next runs the next step, returning the error of a step the game can not go without.
+func (l *Loader) Next() error {
+	if l.Finished() {
+		return nil
+	}
+	s := l.Steps[l.Done]
+	l.Done++
+	if err := s.Load(); err != nil {
+		if s.Optional {
+			log.Printf("loading %s: %v", s.Name, err)
+			return nil
+		}
+		return fmt.Errorf("loading %s: %v", s.Name, err)
+	}
+	return nil
+}
+
+// LoadAll runs all the steps at once.
+func (l *Loader) LoadAll() error {
+	for !l.Finished() {
+		if err := l.Next(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *Loader) Draw(r *ebiten.Image) {
+	r.Fill(color.Black)
+	x, y, w := 20.0, float64(Viewport.Height/2), float64(Viewport.Width-40)
+	FillRect(r, x, y, w, 4, color.Gray{Y: 0x40})
+	FillRect(r, x, y, w*l.Progress(), 4, color.White)
+	if DefaultFont != nil {
+		DrawTextAligned(r, T("LOADING"), Viewport.Width/2, Viewport.Height/2-20, AlignCenter)
+	}
+}
+
+// AssetFS is where the assets are loaded from, sounds included.
+var AssetFS fs.FS = asset
+
+// AudioError is why there is no sound, the game runs muted then.
+var AudioError error
+
+// AssetSteps load the font first for the loading screen, the audio last.
+func AssetSteps(fsys fs.FS) []LoadStep {
+	return []LoadStep{
+		{"font", func() error { return LoadFont(fsys) }, false},
+		{"theme", func() error { return LoadThemes(fsys) }, false},
+		{"stages", func() (err error) {
+			Stages, err = LoadStages(fsys, "asset/stage")
+			return
+		}, false},
+		{"music", func() error {
+			if AudioError = LoadMusic(fsys); AudioError != nil {
+				for _, l := range MusicLayers {
+					l.Voice = nil
+				}
+				MusicChannel.Voices = nil
+			}
+			return AudioError
+		}, true},
+	}
+}
+
+func LoadFont(fsys fs.FS) error {
+	f, err := fsys.Open("asset/font.png")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	sheet, err := png.Decode(f)
+	if err != nil {
+		return fmt.Errorf("font.png: %v", err)
+	}
+	DefaultFont = NewFont(sheet, FontWidth, FontHeight, 16, ' ')
+	DefaultFont.Face = bitmapfont.Face
+	DefaultFont.FaceBaseline = 12
+	return nil
+}
+
+// LoadThemes loads the classic theme, then the one chosen in the settings.
+// Failing the chosen one leaves the classic theme in use.
+func LoadThemes(fsys fs.FS) error {
+	theme, err := LoadTheme(fsys, ClassicThemeDir)
+	if err != nil {
+		return err
+	}
+	UseTheme(theme)
+	if err := SwitchTheme(Setting.ThemeName()); err != nil {
+		log.Println(err)
+	}
+	return nil
+}
+
+func decodeVorbis(fsys fs.FS, name string) (*vorbis.Stream, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	rs, ok := f.(io.ReadSeeker)
+	if !ok {
+		return nil, fmt.Errorf("%s: can not seek", name)
+	}
+	s, err := vorbis.Decode(AudioCtx, rs)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return s, nil
+}
+
+func loadLoop(src io.ReadSeeker, length int64) (*Voice, error) {
+	p, err := audio.NewPlayer(AudioCtx, audio.NewInfiniteLoop(src, length))
+	if err != nil {
+		return nil, err
+	}
+	return MusicChannel.Add(p, 0), nil
+}
+
+// LoadMusic makes the audio context and the players of the music layers.
+// The recorded layers must loop at the same length to stay in step, the made ones are made to it.
+func LoadMusic(fsys fs.FS) error {
+	if AudioCtx == nil {
+		AudioCtx = audio.NewContext(SampleRate)
+	}
+	var length int64
+	for _, l := range MusicLayers {
+		if l.File == "" {
+			continue
+		}
+		s, err := decodeVorbis(fsys, l.File)
+		if err != nil {
+			return err
+		}
+		if length == 0 {
+			length = s.Length()
+		} else if s.Length() != length {
+			return fmt.Errorf("%s: loop of %d bytes, not %d as the other layers", l.File, s.Length(), length)
+		}
+		if l.Voice, err = loadLoop(s, s.Length()); err != nil {
+			return err
+		}
+	}
+	for _, l := range MusicLayers {
+		if l.Synth == nil {
+			continue
+		}
+		data := l.Synth(int(length / 4))
+		var err error
+		if l.Voice, err = loadLoop(bytes.NewReader(data), int64(len(data))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Load shows the loading screen until the steps are done, then the title.
+func (g *Game) Load(steps []LoadStep) {
+	g.Loader = NewLoader(steps)
+	g.Step = Loading
+}
+
+func (g *Game) UpdateLoading() error {
+	if err := g.Loader.Next(); err != nil {
+		return err
+	}
+	if !g.Loader.Finished() {
+		return nil
+	}
+	g.Loader = nil
+	g.Initialize()
+	g.StartMusic()
+	if AudioError != nil {
+		g.Toast = T("NO SOUND")
+		g.ToastWait = ToastFrame
+	}
+	return nil
+}
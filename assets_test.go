@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// TestMain loads what the tests draw and play with, without audio.
+// Nothing is saved outside of the tests.
+func TestMain(m *testing.M) {
+	DefaultStorage = MemoryStorage{}
+	for _, s := range AssetSteps(asset) {
+		if s.Optional {
+			continue
+		}
+		if err := s.Load(); err != nil {
+			fmt.Println(s.Name, err)
+			os.Exit(1)
+		}
+	}
+	os.Exit(m.Run())
+}
+
+func TestLoader(t *testing.T) {
+	var loaded []string
+	step := func(name string, err error, optional bool) LoadStep {
+		return LoadStep{name, func() error {
+			loaded = append(loaded, name)
+			return err
+		}, optional}
+	}
+	type Case struct {
+		t      string
+		steps  []LoadStep
+		loaded string
+		err    string
+	}
+	cases := []Case{
+		Case{"all", []LoadStep{step("a", nil, false), step("b", nil, false)}, "a b", ""},
+		Case{"optional failed", []LoadStep{step("a", errors.New("no"), true), step("b", nil, false)}, "a b", ""},
+		Case{"required failed", []LoadStep{step("a", errors.New("broken"), false), step("b", nil, false)}, "a", "loading a: broken"},
+		Case{"none", nil, "", ""},
+	}
+	for _, cs := range cases {
+		loaded = nil
+		l := NewLoader(cs.steps)
+		err := l.LoadAll()
+		if s := strings.Join(loaded, " "); s != cs.loaded {
+			t.Error(cs.t, "loaded", s)
+		}
+		if (err == nil) != (cs.err == "") || err != nil && err.Error() != cs.err {
+			t.Error(cs.t, err)
+		}
+		if err == nil && l.Progress() != 1 {
+			t.Error(cs.t, "progress", l.Progress())
+		}
+	}
+}
+
+func TestLoadMissingAssets(t *testing.T) {
+	fsys := fstest.MapFS{"asset/font.png": &fstest.MapFile{Data: []byte("not a png")}}
+	if err := LoadFont(fsys); err == nil || !strings.Contains(err.Error(), "font.png") {
+		t.Error("font", err)
+	}
+	if err := LoadThemes(fsys); err == nil {
+		t.Error("theme")
+	}
+}
+
+func TestGameLoading(t *testing.T) {
+	g := NewGame()
+	var steps []LoadStep
+	for _, s := range AssetSteps(asset) {
+		if !s.Optional {
+			steps = append(steps, s)
+		}
+	}
+	g.Load(steps)
+	for i := 0; i < len(steps); i++ {
+		if g.Step != Loading {
+			t.Fatal("step", i, g.Step)
+		}
+		if err := g.Update(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if g.Step != Title || g.Loader != nil {
+		t.Error("not at the title", g.Step)
+	}
+	g.Load([]LoadStep{{"broken", func() error { return errors.New("broken") }, false}})
+	if err := g.Update(); err == nil {
+		t.Error("error not returned")
+	}
+}
+
+func TestSoundWithoutAudio(t *testing.T) {
+	if AudioCtx != nil {
+		t.Skip("audio loaded")
+	}
+	if p := LoadSound(S1, 0); p != nil {
+		t.Error("sound without audio")
+	}
+	PlaySound(S1, 0)
+	g := NewGame()
+	g.StartMusic()
+	g.UpdateMusic()
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// ScoreAttack is Endless limited by Turns or by Frames, whichever is set.
+type ScoreAttack struct {
+	Endless
+	Turns  int
+	Frames int
+}
+
+func (m ScoreAttack) Name() string {
+	if m.Turns > 0 {
+		return fmt.Sprintf("Score %d turns", m.Turns)
+	}
+	return fmt.Sprintf("Score %d min", m.Frames/TPS/60)
+}
+
+func (m ScoreAttack) Left(g *Game) int {
+	if m.Turns > 0 {
+		return maxInt(m.Turns-g.Turn, 0)
+	}
+	return maxInt(m.Frames-g.Frames, 0)
+}
+
+func (m ScoreAttack) TurnEnd(g *Game) Step {
+	step := m.Endless.TurnEnd(g)
+	if m.Left(g) == 0 {
+		return GameOver
+	}
+	return step
+}
+
+func (m ScoreAttack) Expired(g *Game) (Step, bool) {
+	return GameOver, m.Left(g) == 0
+}
+
+func (m ScoreAttack) Countdown(g *Game) (string, int) {
+	left := m.Left(g)
+	if m.Turns == 0 {
+		// seconds, rounded up
+		return T("TIME"), (left + TPS - 1) / TPS
+	}
+	return T("TURNS"), left
+}
+
+func (m ScoreAttack) Draw(g *Game, r *ebiten.Image) {
+	if !Viewport.Landscape() {
+		_, left := m.Countdown(g)
+		RenderNumberTop(r, left, g.Board.HUDX(), Viewport.Top())
+	}
+}
+
+// ClearAttack is Endless until Stones are erased, the faster the better.
+type ClearAttack struct {
+	Endless
+	Stones int
+}
+
+func (m ClearAttack) Name() string {
+	return fmt.Sprintf("Clear %d", m.Stones)
+}
+
+func (m ClearAttack) TurnEnd(g *Game) Step {
+	if g.Stats.TotalCleared() >= m.Stones {
+		return Clear
+	}
+	return m.Endless.TurnEnd(g)
+}
+
+func (m ClearAttack) Countdown(g *Game) (string, int) {
+	return T("STONES"), maxInt(m.Stones-g.Stats.TotalCleared(), 0)
+}
+
+func (m ClearAttack) Draw(g *Game, r *ebiten.Image) {
+	if !Viewport.Landscape() {
+		_, left := m.Countdown(g)
+		RenderNumberTop(r, left, g.Board.HUDX(), Viewport.Top())
+	}
+}
+
+func (m ClearAttack) Record(g *Game) (Record, bool) {
+	return Record{Score: g.Score, Turns: g.Turn, Frames: g.Frames}, g.Stats.TotalCleared() >= m.Stones
+}
+
+func (m ClearAttack) Better(a, b Record) bool {
+	return a.Frames < b.Frames
+}
+
+func (m ClearAttack) Format(r Record) string {
+	return FormatSeconds(r.Frames)
+}
+
+func AttackModes() []Ranked {
+	return []Ranked{
+		ScoreAttack{Turns: 30},
+		ScoreAttack{Frames: 3 * 60 * TPS},
+		ClearAttack{Stones: 100},
+	}
+}
+
+func RankedModes() []Ranked {
+	modes := []Ranked{Endless{}}
+	modes = append(modes, AttackModes()...)
+	return append(modes, Zen{})
+}
+
+func AttackMenu() *Menu {
+	m := &Menu{Title: T("Attack")}
+	for _, mode := range AttackModes() {
+		mode := mode.(Mode)
+		m.Items = append(m.Items, MenuItem{mode.(Ranked).Name(), func(g *Game) { g.Start(mode) }})
+	}
+	m.Items = append(m.Items, MenuItem{T("Back"), func(g *Game) { g.OpenMenu(MainMenu()) }})
+	return m
+}
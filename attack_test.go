@@ -0,0 +1,85 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestAttackEnd(t *testing.T) {
+	defer func(s Storage) { DefaultStorage = s }(DefaultStorage)
+	DefaultStorage = MemoryStorage{}
+
+	type Case struct {
+		t       string
+		mode    Mode
+		turn    int
+		frames  int
+		cleared int
+		expired bool
+		step    Step
+	}
+	cases := []Case{
+		Case{"turns left", ScoreAttack{Turns: 30}, 29, 0, 0, false, Move},
+		Case{"turns over", ScoreAttack{Turns: 30}, 30, 0, 0, true, GameOver},
+		Case{"time left", ScoreAttack{Frames: 60 * TPS}, 1, 60*TPS - 1, 0, false, Move},
+		Case{"time over", ScoreAttack{Frames: 60 * TPS}, 1, 60 * TPS, 0, true, GameOver},
+		Case{"stones left", ClearAttack{Stones: 100}, 1, 0, 99, false, Move},
+		Case{"stones cleared", ClearAttack{Stones: 100}, 1, 0, 100, false, Clear},
+	}
+	for _, cs := range cases {
+		g := NewGame()
+		g.Start(cs.mode)
+		g.Turn = cs.turn
+		g.Frames = cs.frames
+		g.Stats.Cleared[Red] = cs.cleared
+		if _, e := g.Mode.Expired(g); e != cs.expired {
+			t.Error(cs.t, "expired", e, cs.expired)
+		}
+		if step := g.Mode.TurnEnd(g); step != cs.step {
+			t.Error(cs.t, "step", step, cs.step)
+		}
+	}
+}
+
+func TestGameEndRecords(t *testing.T) {
+	defer func(s Storage) { DefaultStorage = s }(DefaultStorage)
+	DefaultStorage = MemoryStorage{}
+
+	g := NewGame()
+	g.Start(ClearAttack{Stones: 100})
+	g.End(GameOver)
+	if g.Rank != -1 {
+		t.Error("unfinished clear attack ranked", g.Rank)
+	}
+	g.Start(ClearAttack{Stones: 100})
+	g.Stats.Cleared[Red] = 100
+	g.Frames = 300
+	g.End(Clear)
+	if g.Rank != 0 {
+		t.Error("clear attack not ranked", g.Rank)
+	}
+	if records := LoadRecords(ClearAttack{Stones: 100}); len(records) != 1 || records[0].Frames != 300 {
+		t.Error("records", records)
+	}
+}
+
+func TestAttackCountdown(t *testing.T) {
+	type Case struct {
+		t     string
+		mode  Countdown
+		turn  int
+		frame int
+		left  int
+	}
+	cases := []Case{
+		Case{"turns", ScoreAttack{Turns: 30}, 10, 0, 20},
+		Case{"seconds rounded up", ScoreAttack{Frames: 3 * 60 * TPS}, 0, TPS + 1, 3*60 - 1},
+		Case{"stones", ClearAttack{Stones: 100}, 0, 0, 100},
+	}
+	for _, cs := range cases {
+		g := NewGame()
+		g.Turn, g.Frames = cs.turn, cs.frame
+		if _, left := cs.mode.Countdown(g); left != cs.left {
+			t.Error(cs.t, left, cs.left)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"encoding/binary"
+	"io"
+	"log"
+	"math"
+	"math/rand"
+
+	"github.com/hajimehoshi/ebiten/v2/audio"
+)
+
+const (
+	SampleRate = 44100
+	// most sound effects playing at once, the oldest stops for a new one
+	MaxVoices = 8
+	// semitones the chain sound goes up every round of the four sounds
+	ChainStep = 2
+	MaxPitch  = 12
+)
+
+type Sound int
+
+const (
+	S1 Sound = iota
+	S2
+	S3
+	S4
+	SoundCut
+	SoundLand
+	SoundJammerDrop
+	SoundJammerBreak
+	SoundGameOver
+)
+
+var SoundNameMap map[string]Sound = map[string]Sound{
+	"asset/S1.ogg": S1,
+	"asset/S2.ogg": S2,
+	"asset/S3.ogg": S3,
+	"asset/S4.ogg": S4,
+}
+
+var AudioCtx *audio.Context
+
+// Voice is a player with its own gain, under the volume of its channel.
+type Voice struct {
+	Player *audio.Player
+	Gain   float64
+}
+
+// Channel mixes voices at the volume of a setting.
+// Voices of music are kept, sound effects are let go when finished.
+type Channel struct {
+	Volume func() float64
+	// 0 for no limit
+	MaxVoices int
+	Voices    []*Voice
+}
+
+var (
+	MusicChannel = &Channel{Volume: func() float64 { return Setting.MusicVolume() }}
+	SFXChannel   = &Channel{Volume: func() float64 { return Setting.SoundVolume() }, MaxVoices: MaxVoices}
+)
+
+// Add puts a player on the channel, to be played by the caller.
+func (c *Channel) Add(p *audio.Player, gain float64) *Voice {
+	v := &Voice{p, gain}
+	p.SetVolume(c.Volume() * gain)
+	c.Voices = append(c.Voices, v)
+	return v
+}
+
+// Play plays data as a new voice, so that the sound does not cut off the one before.
+func (c *Channel) Play(data []byte, gain float64) *Voice {
+	var voices []*Voice
+	for _, v := range c.Voices {
+		if v.Player.IsPlaying() {
+			voices = append(voices, v)
+		}
+	}
+	for c.MaxVoices > 0 && len(voices) >= c.MaxVoices {
+		voices[0].Player.Pause()
+		voices = voices[1:]
+	}
+	c.Voices = voices
+	v := c.Add(audio.NewPlayerFromBytes(AudioCtx, data), gain)
+	v.Player.Play()
+	return v
+}
+
+// SetGain changes the gain of a voice on the channel.
+func (c *Channel) SetGain(v *Voice, gain float64) {
+	v.Gain = gain
+	v.Player.SetVolume(c.Volume() * gain)
+}
+
+// Apply sets the volume after the settings changed.
+func (c *Channel) Apply() {
+	for _, v := range c.Voices {
+		v.Player.SetVolume(c.Volume() * v.Gain)
+	}
+}
+
+// ChainSound keeps the cycle of four sounds, going up by ChainStep semitones every round.
+func ChainSound(chain int) (Sound, int) {
+	return []Sound{S4, S1, S2, S3}[chain%4], minInt(chain/4*ChainStep, MaxPitch)
+}
+
+// SoundHandler plays the sounds of the events.
+func SoundHandler(g *Game, e Event) {
+	switch e := e.(type) {
+	case CutPlaced:
+		PlaySound(SoundCut, 0)
+	case StonesLanded:
+		// a little different every time
+		PlaySound(SoundLand, rand.Intn(3)-1)
+	case StonesMatched:
+		if len(e.Match.Jammers) > 0 {
+			PlaySound(SoundJammerBreak, 0)
+		}
+	case ChainAdvanced:
+		PlaySound(ChainSound(e.Chain))
+	case JammerDropped:
+		PlaySound(SoundJammerDrop, 0)
+	case GameEnded:
+		if e.Step == GameOver {
+			PlaySound(SoundGameOver, 0)
+		}
+	}
+}
+
+// PlaySound plays s shifted by semitones on the SFX channel.
+// It plays nothing while the browser holds the audio back, rather than all at once later.
+func PlaySound(s Sound, semitones int) {
+	if data := LoadSound(s, semitones); data != nil && AudioCtx.IsReady() {
+		SFXChannel.Play(data, 1)
+	}
+}
+
+type pitched struct {
+	Sound     Sound
+	Semitones int
+}
+
+// SoundData are the decoded sounds, nil for the ones failed to load.
+var SoundData map[pitched][]byte = map[pitched][]byte{}
+
+// LoadSound decodes or makes a sound the first time it is played.
+// It is nil without audio, or when the sound failed to load.
+func LoadSound(s Sound, semitones int) []byte {
+	key := pitched{s, semitones}
+	if data, ok := SoundData[key]; ok {
+		return data
+	}
+	if AudioCtx == nil || AudioError != nil {
+		return nil
+	}
+	var data []byte
+	if semitones != 0 {
+		if base := LoadSound(s, 0); base != nil {
+			data = Pitch(base, math.Pow(2, float64(semitones)/12))
+		}
+	} else if synth, ok := Synths[s]; ok {
+		data = synth()
+	} else {
+		data = decodeSound(s)
+	}
+	// not to try again after a failure
+	SoundData[key] = data
+	return data
+}
+
+func decodeSound(s Sound) []byte {
+	for name, sn := range SoundNameMap {
+		if sn != s {
+			continue
+		}
+		v, err := decodeVorbis(AssetFS, name)
+		if err != nil {
+			log.Println(err)
+			return nil
+		}
+		data, err := io.ReadAll(v)
+		if err != nil {
+			log.Println(name, err)
+			return nil
+		}
+		return data
+	}
+	return nil
+}
+
+// Pitch resamples 16 bit stereo data to play ratio times as high and as fast.
+func Pitch(data []byte, ratio float64) []byte {
+	frames := len(data) / 4
+	n := int(float64(frames) / ratio)
+	out := make([]byte, n*4)
+	sample := func(i, ch int) float64 {
+		if i >= frames {
+			i = frames - 1
+		}
+		return float64(int16(binary.LittleEndian.Uint16(data[i*4+ch*2:])))
+	}
+	for i := 0; i < n; i++ {
+		pos := float64(i) * ratio
+		j, f := int(pos), pos-math.Floor(pos)
+		for ch := 0; ch < 2; ch++ {
+			v := sample(j, ch)*(1-f) + sample(j+1, ch)*f
+			binary.LittleEndian.PutUint16(out[i*4+ch*2:], uint16(int16(v)))
+		}
+	}
+	return out
+}
+
+// Synth makes 16 bit stereo data of seconds long from wave, a function of time giving -1 to 1.
+func Synth(seconds float64, wave func(t float64) float64) []byte {
+	return synthFrames(int(seconds*SampleRate), func(i int) float64 {
+		return wave(float64(i) / SampleRate)
+	})
+}
+
+// Pattern makes a loop of frames split into even steps, hit on every step
+// as loud as its accent, the accents repeating. t of hit is from the start of the step.
+func Pattern(frames, steps int, accents []float64, hit func(t float64) float64) []byte {
+	length := float64(frames) / float64(steps)
+	return synthFrames(frames, func(i int) float64 {
+		step := int(float64(i) / length)
+		a := accents[step%len(accents)]
+		if a == 0 {
+			return 0
+		}
+		return a * hit((float64(i)-float64(step)*length)/SampleRate)
+	})
+}
+
+func synthFrames(n int, wave func(i int) float64) []byte {
+	out := make([]byte, n*4)
+	for i := 0; i < n; i++ {
+		v := math.Max(-1, math.Min(1, wave(i)))
+		s := uint16(int16(v * 0.5 * math.MaxInt16))
+		binary.LittleEndian.PutUint16(out[i*4:], s)
+		binary.LittleEndian.PutUint16(out[i*4+2:], s)
+	}
+	return out
+}
+
+func sine(freq, t float64) float64 {
+	return math.Sin(2 * math.Pi * freq * t)
+}
+
+// Synths make the sounds there are no files for.
+var Synths map[Sound]func() []byte = map[Sound]func() []byte{
+	// a short high blip
+	SoundCut: func() []byte {
+		return Synth(0.06, func(t float64) float64 {
+			return sine(880, t) * math.Exp(-t*60)
+		})
+	},
+	// a low thud, falling in pitch
+	SoundLand: func() []byte {
+		return Synth(0.12, func(t float64) float64 {
+			return sine(160-400*t, t) * math.Exp(-t*30)
+		})
+	},
+	// a square wave sliding down
+	SoundJammerDrop: func() []byte {
+		return Synth(0.25, func(t float64) float64 {
+			return math.Copysign(0.5, sine(400-1000*t, t)) * math.Exp(-t*8)
+		})
+	},
+	// a burst of noise
+	SoundJammerBreak: func() []byte {
+		r := rand.New(rand.NewSource(1))
+		return Synth(0.15, func(t float64) float64 {
+			return (r.Float64()*2 - 1) * math.Exp(-t*25)
+		})
+	},
+	// three notes going down
+	SoundGameOver: func() []byte {
+		notes := []float64{440, 349.23, 261.63}
+		return Synth(0.9, func(t float64) float64 {
+			i := minInt(int(t/0.3), len(notes)-1)
+			nt := t - float64(i)*0.3
+			return sine(notes[i], t) * math.Exp(-nt*6)
+		})
+	},
+}
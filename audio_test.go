@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestSynths(t *testing.T) {
+	for s, synth := range Synths {
+		data := synth()
+		if len(data) == 0 || len(data)%4 != 0 {
+			t.Error(s, "length", len(data))
+		}
+		loud := false
+		for i := 0; i < len(data); i += 2 {
+			if binary.LittleEndian.Uint16(data[i:]) != 0 {
+				loud = true
+				break
+			}
+		}
+		if !loud {
+			t.Error(s, "silent")
+		}
+	}
+}
+
+func TestPitch(t *testing.T) {
+	data := Synth(0.1, func(t float64) float64 { return sine(440, t) })
+	type Case struct {
+		t      string
+		ratio  float64
+		frames int
+	}
+	cases := []Case{
+		Case{"same", 1, len(data) / 4},
+		Case{"octave up", 2, len(data) / 8},
+		Case{"octave down", 0.5, len(data) / 2},
+	}
+	for _, cs := range cases {
+		if n := len(Pitch(data, cs.ratio)) / 4; n != cs.frames {
+			t.Error(cs.t, n, cs.frames)
+		}
+	}
+}
+
+func TestChainSound(t *testing.T) {
+	type Case struct {
+		chain     int
+		sound     Sound
+		semitones int
+	}
+	cases := []Case{
+		Case{1, S1, 0},
+		Case{3, S3, 0},
+		Case{4, S4, ChainStep},
+		Case{5, S1, ChainStep},
+		Case{9, S1, 2 * ChainStep},
+		Case{100, S4, MaxPitch},
+	}
+	for _, cs := range cases {
+		s, st := ChainSound(cs.chain)
+		if s != cs.sound || st != cs.semitones {
+			t.Error(cs.chain, s, st, cs.sound, cs.semitones)
+		}
+	}
+}
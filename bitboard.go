@@ -0,0 +1,183 @@
+package main
+
+import (
+	"math/bits"
+)
+
+// BitBoard is a value type board for bots and simulations.
+// Mask[c][x] has bit y set when cell x, y holds a stone of color c,
+// so copying a BitBoard is a clone.
+type BitBoard struct {
+	Mask [Jammer + 1][BoardWidth]uint16
+}
+
+func NewBitBoard(b *Board) BitBoard {
+	var bb BitBoard
+	for cx := 0; cx < BoardWidth; cx++ {
+		for cy := 0; cy < BoardHeight; cy++ {
+			bb.Set(cx, cy, b.ColorAt(cx, cy))
+		}
+	}
+	return bb
+}
+
+// Board converts back to a renderable board.
+func (bb *BitBoard) Board() *Board {
+	b := NewBoard()
+	b.Initialize()
+	for cx := 0; cx < BoardWidth; cx++ {
+		for cy := 0; cy < BoardHeight; cy++ {
+			if c, ok := b.At(cx, cy); ok {
+				if color := bb.At(cx, cy); color != None {
+					*c = &Stone{Color: color}
+				} else {
+					*c = nil
+				}
+			}
+		}
+	}
+	// not settled, MarkChanged finds the matches already there
+	return b
+}
+
+func (bb *BitBoard) At(cx, cy int) Color {
+	if cx < 0 || BoardWidth <= cx || cy < 0 || BoardHeight <= cy {
+		return None
+	}
+	bit := uint16(1) << uint(cy)
+	for c := range bb.Mask {
+		if bb.Mask[c][cx]&bit != 0 {
+			return Color(c)
+		}
+	}
+	return None
+}
+
+func (bb *BitBoard) Set(cx, cy int, color Color) {
+	if cx < 0 || BoardWidth <= cx || cy < 0 || BoardHeight <= cy {
+		return
+	}
+	bit := uint16(1) << uint(cy)
+	for c := range bb.Mask {
+		bb.Mask[c][cx] &^= bit
+	}
+	if color != None && int(color) < len(bb.Mask) {
+		bb.Mask[color][cx] |= bit
+	}
+}
+
+func (bb *BitBoard) Occupied(cx int) uint16 {
+	var m uint16
+	for c := range bb.Mask {
+		m |= bb.Mask[c][cx]
+	}
+	return m
+}
+
+// HeightAt works like Board.HeightAt.
+func (bb *BitBoard) HeightAt(cx int) int {
+	return bits.TrailingZeros16(bb.Occupied(cx))
+}
+
+// Drop stacks colors on column cx, colors[0] at the bottom like FixPick.
+// The top row is kept empty as AdjustPick does.
+func (bb *BitBoard) Drop(cx int, colors []Color) bool {
+	y := bb.HeightAt(cx) - 1
+	if y-len(colors)+1 < 1 {
+		return false
+	}
+	for i, c := range colors {
+		bb.Set(cx, y-i, c)
+	}
+	return true
+}
+
+// Fall drops every stone at once. Walls stay where they are.
+func (bb *BitBoard) Fall() bool {
+	falled := false
+	for cx := 0; cx < BoardWidth; cx++ {
+		var column [BoardHeight]Color
+		for cy := 0; cy < BoardHeight; cy++ {
+			column[cy] = bb.At(cx, cy)
+		}
+		to := BoardHeight - 1
+		for cy := BoardHeight - 1; cy >= 0; cy-- {
+			switch column[cy] {
+			case None:
+			case Wall:
+				to = cy - 1
+			default:
+				if to != cy {
+					bb.Set(cx, to, column[cy])
+					bb.Set(cx, cy, None)
+					falled = true
+				}
+				to--
+			}
+		}
+	}
+	return falled
+}
+
+// Match returns cells MarkErase would mark, one bit per cell.
+func (bb *BitBoard) Match() [BoardWidth]uint16 {
+	var erase [BoardWidth]uint16
+	for _, c := range []Color{Red, Blue, Green, Yellow, Pink, Orange} {
+		m := &bb.Mask[c]
+		for x := 0; x < BoardWidth; x++ {
+			// vertical
+			v := m[x] & (m[x] >> 1) & (m[x] >> 2)
+			erase[x] |= v | v<<1 | v<<2
+			if x+2 >= BoardWidth {
+				continue
+			}
+			// horizontal, right down and right up, starting at x
+			h := m[x] & m[x+1] & m[x+2]
+			d := m[x] & (m[x+1] >> 1) & (m[x+2] >> 2)
+			u := m[x] & (m[x+1] << 1) & (m[x+2] << 2)
+			erase[x] |= h | d | u
+			erase[x+1] |= h | d<<1 | u>>1
+			erase[x+2] |= h | d<<2 | u>>2
+		}
+	}
+	jammer := &bb.Mask[Jammer]
+	var near [BoardWidth]uint16
+	for x := 0; x < BoardWidth; x++ {
+		near[x] |= erase[x]<<1 | erase[x]>>1
+		if x > 0 {
+			near[x] |= erase[x-1]
+		}
+		if x+1 < BoardWidth {
+			near[x] |= erase[x+1]
+		}
+	}
+	for x := 0; x < BoardWidth; x++ {
+		erase[x] |= near[x] & jammer[x]
+	}
+	return erase
+}
+
+func (bb *BitBoard) Erase(erase [BoardWidth]uint16) int {
+	num := 0
+	for x := 0; x < BoardWidth; x++ {
+		num += bits.OnesCount16(erase[x])
+		for c := range bb.Mask {
+			bb.Mask[c][x] &^= erase[x]
+		}
+	}
+	return num
+}
+
+// Settle falls and erases until nothing matches,
+// returning the chain length and the number of erased cells.
+func (bb *BitBoard) Settle() (chain, erased int) {
+	for {
+		bb.Fall()
+		num := bb.Erase(bb.Match())
+		if num == 0 {
+			return chain, erased
+		}
+		chain++
+		erased += num
+	}
+}
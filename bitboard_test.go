@@ -0,0 +1,125 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestBitBoardConvert(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 50; i++ {
+		b := NewBoard()
+		b.Initialize()
+		randomDrop(b, rnd, 40)
+		bb := NewBitBoard(b)
+		b2 := bb.Board()
+		for cx := 0; cx < BoardWidth; cx++ {
+			for cy := 0; cy < BoardHeight; cy++ {
+				if b.ColorAt(cx, cy) != b2.ColorAt(cx, cy) {
+					t.Error(i, "color mismatch", cx, cy, b.ColorAt(cx, cy), b2.ColorAt(cx, cy))
+				}
+			}
+			if b.HeightAt(cx) != bb.HeightAt(cx) {
+				t.Error(i, "height mismatch", cx, b.HeightAt(cx), bb.HeightAt(cx))
+			}
+		}
+	}
+}
+
+func TestBitBoardConvertMatch(t *testing.T) {
+	b := NewBoard()
+	b.Initialize()
+	for x := 1; x <= 3; x++ {
+		b.Cell[x][BoardHeight-1] = &Stone{Color: Red}
+	}
+	bb := NewBitBoard(b)
+	if r := bb.Board().MarkChanged(); len(r.Groups) != 1 || r.Num() != 3 {
+		t.Error("match lost in the conversion", r)
+	}
+}
+
+func TestBitBoardMatch(t *testing.T) {
+	rnd := rand.New(rand.NewSource(2))
+	for i := 0; i < 200; i++ {
+		b := NewBoard()
+		b.Initialize()
+		randomDrop(b, rnd, rnd.Intn(60))
+		bb := NewBitBoard(b)
+		b.MarkErase()
+		erase := bb.Match()
+		for cx := 0; cx < BoardWidth; cx++ {
+			for cy := 0; cy < BoardHeight; cy++ {
+				erased := false
+				if c, ok := b.At(cx, cy); ok && *c != nil {
+					erased = (*c).Erased
+				}
+				if marked := erase[cx]&(1<<uint(cy)) != 0; marked != erased {
+					t.Error(i, "erase mismatch", cx, cy, erased, marked)
+				}
+			}
+		}
+	}
+}
+
+func TestBitBoardSettle(t *testing.T) {
+	rnd := rand.New(rand.NewSource(3))
+	for i := 0; i < 100; i++ {
+		b := NewBoard()
+		b.Initialize()
+		randomDrop(b, rnd, rnd.Intn(60))
+		bb := NewBitBoard(b)
+		clone := bb
+
+		chain := 0
+		for {
+			for b.FallStone() {
+			}
+			if b.MarkErase().Num() == 0 {
+				break
+			}
+			b.Erase()
+			chain++
+		}
+		c, _ := bb.Settle()
+		if c != chain {
+			t.Error(i, "chain", chain, c)
+		}
+		if NewBitBoard(b) != bb {
+			t.Error(i, "settled board mismatch")
+		}
+		if chain > 0 && clone == bb {
+			t.Error(i, "clone changed with the original")
+		}
+	}
+}
+
+func TestBitBoardDrop(t *testing.T) {
+	b := NewBoard()
+	b.Initialize()
+	bb := NewBitBoard(b)
+	if !bb.Drop(2, []Color{Red, Blue, Red}) {
+		t.FailNow()
+	}
+	for i, c := range []Color{Red, Blue, Red} {
+		if got := bb.At(2, BoardHeight-2-i); got != c {
+			t.Error("drop", i, c, got)
+		}
+	}
+	if bb.Drop(2, make([]Color, BoardHeight)) {
+		t.Error("drop must fail over the top")
+	}
+}
+
+func BenchmarkBitBoardSettle(b *testing.B) {
+	boards := benchmarkBoards()
+	var bbs []BitBoard
+	for _, bd := range boards {
+		bbs = append(bbs, NewBitBoard(bd))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bb := bbs[i%len(bbs)]
+		bb.Drop(1+i%(BoardWidth-2), []Color{Red, Blue, Green})
+		bb.Settle()
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"log"
+	"math/rand"
+	"time"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Daily is Endless with stones and jammers seeded by the date,
+// so everyone plays the same sequence on the same day however they cut.
+type Daily struct {
+	Endless
+	Date string
+	// games after the attempt of the day are not recorded
+	Practice bool
+}
+
+// DailyResult is the only attempt of a day, saved when it starts
+// and Finished with the result at the end.
+type DailyResult struct {
+	Date     string `json:"date"`
+	Score    int    `json:"score"`
+	Turns    int    `json:"turns"`
+	MaxChain int    `json:"max_chain"`
+	Finished bool   `json:"finished"`
+}
+
+// Today is the date in UTC, the same day all over the world.
+func Today() string {
+	return time.Now().UTC().Format("2006-01-02")
+}
+
+// DailyLevelStones are dealt at every color level, about the stones of ColorLevel's 24 turns.
+const DailyLevelStones = 48
+
+// DailySeed seeds the stream of stones or jammers of the date.
+func DailySeed(date, stream string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("cutnalign/daily/" + date + "/" + stream))
+	return int64(h.Sum64())
+}
+
+func NewDaily() Daily {
+	return Daily{Date: Today()}
+}
+
+func (m Daily) Start(g *Game) {
+	if _, ok := LoadDaily(m.Date); ok && !m.Practice {
+		// the day was played, the game goes on as practice
+		m.Practice = true
+		g.Mode = m
+	}
+	g.Rand = rand.New(rand.NewSource(DailySeed(m.Date, "stones")))
+	g.JammerRand = rand.New(rand.NewSource(DailySeed(m.Date, "jammers")))
+	g.Back = func(g *Game) { g.OpenMenu(DailyMenu(m.Date)) }
+	if m.Practice {
+		return
+	}
+	// quitting or closing the game uses up the attempt too
+	if err := SaveData(DailyKey(m.Date), DailyResult{Date: m.Date}); err != nil {
+		log.Println(err)
+	}
+}
+
+// Next levels up by the stones dealt, not by the turns they took.
+func (m Daily) Next(g *Game) *Stone {
+	return DealColors(g, minInt(3+g.Drawn/DailyLevelStones, 6))
+}
+
+func (m Daily) TurnEnd(g *Game) Step {
+	step := m.Endless.TurnEnd(g)
+	if step != Move {
+		m.Save(g)
+	}
+	return step
+}
+
+// Save finishes the attempt of the day with the result of the game.
+func (m Daily) Save(g *Game) {
+	if r, _ := LoadDaily(m.Date); m.Practice || r.Finished {
+		return
+	}
+	r := DailyResult{Date: m.Date, Score: g.Score, Turns: g.Turn, MaxChain: g.Stats.MaxChain, Finished: true}
+	if err := SaveData(DailyKey(m.Date), r); err != nil {
+		log.Println(err)
+	}
+}
+
+func (m Daily) Draw(g *Game, r *ebiten.Image) {
+	DrawText(r, Tf("DAILY %s", m.Date), Viewport.Left()+2, Viewport.Top())
+}
+
+// Record keeps daily games out of the Endless leaderboard.
+func (m Daily) Record(g *Game) (Record, bool) {
+	return Record{}, false
+}
+
+func DailyKey(date string) string {
+	return "daily/" + date
+}
+
+func LoadDaily(date string) (DailyResult, bool) {
+	var r DailyResult
+	if err := LoadData(DailyKey(date), &r); err != nil {
+		log.Println(err)
+	}
+	return r, r.Date == date
+}
+
+// ShareText is a summary to paste into chat.
+func (r DailyResult) ShareText() string {
+	return fmt.Sprintf("cut'n'align daily %s\nscore %d\nturns %d\nmax chain %d\n", r.Date, r.Score, r.Turns, r.MaxChain)
+}
+
+func DailyMenu(date string) *Menu {
+	m := &Menu{Title: Tf("Daily %s", date)}
+	if r, ok := LoadDaily(date); ok {
+		m.Items = append(m.Items,
+			MenuItem{Tf("score %d", r.Score), nil},
+			MenuItem{Tf("turns %d  chain %d", r.Turns, r.MaxChain), nil},
+			MenuItem{T("Share"), func(g *Game) {
+				if err := ShareText(r.ShareText()); err != nil {
+					log.Println(err)
+				}
+			}},
+			MenuItem{T("Practice"), func(g *Game) { g.Start(Daily{Date: date, Practice: true}) }},
+		)
+	} else {
+		m.Items = append(m.Items, MenuItem{T("Play"), func(g *Game) { g.Start(Daily{Date: date}) }})
+	}
+	m.Items = append(m.Items, MenuItem{T("Back"), func(g *Game) { g.OpenMenu(MainMenu()) }})
+	return m
+}
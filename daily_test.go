@@ -0,0 +1,173 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDailySequence(t *testing.T) {
+	defer func(s Storage) { DefaultStorage = s }(DefaultStorage)
+	DefaultStorage = MemoryStorage{}
+
+	sequence := func(date string) []Color {
+		g := NewGame()
+		g.Start(Daily{Date: date, Practice: true})
+		var colors []Color
+		for i := 0; i < 30; i++ {
+			colors = append(colors, g.Next().Color)
+		}
+		g.CauseJammer()
+		for x := 1; x < BoardWidth-1; x++ {
+			colors = append(colors, g.Board.ColorAt(x, BoardHeight-2))
+		}
+		return colors
+	}
+	a, b, c := sequence("2026-10-19"), sequence("2026-10-19"), sequence("2026-10-20")
+	if !colorsEqual(a, b) {
+		t.Error("same date differs", a, b)
+	}
+	if colorsEqual(a, c) {
+		t.Error("next date is the same", a, c)
+	}
+}
+
+func TestDailySequenceByCuts(t *testing.T) {
+	defer func(s Storage) { DefaultStorage = s }(DefaultStorage)
+	DefaultStorage = MemoryStorage{}
+
+	// play deals the stones and drops the jammers of turns cut by cut
+	play := func(cut func(turn int) (x, n int)) ([]Color, []int) {
+		g := NewGame()
+		g.Start(Daily{Date: "2026-10-19", Practice: true})
+		var jammers []int
+		g.Events.Subscribe(func(g *Game, e Event) {
+			if e, ok := e.(JammerDropped); ok {
+				for _, p := range e.Points {
+					jammers = append(jammers, p.x)
+				}
+			}
+		})
+		var stones []Color
+		seen := map[*Stone]bool{}
+		deal := func() {
+			for _, s := range g.Pick {
+				if !seen[s] {
+					seen[s] = true
+					stones = append(stones, s.Color)
+				}
+			}
+		}
+		deal()
+		for turn := 0; turn < 20 && g.Step == Move; turn++ {
+			x, n := cut(turn)
+			g.AdjustPick(x, g.PickBottom(x)-n+1)
+			g.FixPick()
+			for i := 0; i < 100 && g.Step != Move && g.Step != GameOver; i++ {
+				g.Update()
+			}
+			deal()
+		}
+		return stones, jammers
+	}
+	stonesA, jammersA := play(func(turn int) (int, int) { return 1 + turn%6, 1 })
+	stonesB, jammersB := play(func(turn int) (int, int) { return 6 - turn%6, 3 })
+	n := minInt(len(stonesA), len(stonesB))
+	if n < 20 || !colorsEqual(stonesA[:n], stonesB[:n]) {
+		t.Error("stones differ", stonesA, stonesB)
+	}
+	j := minInt(len(jammersA), len(jammersB))
+	if j < 3 || !reflect.DeepEqual(jammersA[:j], jammersB[:j]) {
+		t.Error("jammers differ", jammersA, jammersB)
+	}
+}
+
+func TestDailyLevel(t *testing.T) {
+	g := NewGame()
+	g.Start(Daily{Date: "2026-10-19", Practice: true})
+	g.Buffer = nil
+	g.Drawn = 2 * DailyLevelStones
+	colors := map[Color]bool{}
+	for i := 0; i < 5; i++ {
+		colors[g.Next().Color] = true
+	}
+	if len(colors) != 5 {
+		t.Error("level by stones", colors)
+	}
+}
+
+func colorsEqual(a, b []Color) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestDailyOneAttempt(t *testing.T) {
+	defer func(s Storage) { DefaultStorage = s }(DefaultStorage)
+	DefaultStorage = MemoryStorage{}
+
+	m := Daily{Date: "2026-10-19"}
+	g := NewGame()
+	for _, score := range []int{100, 200} {
+		g.Start(m)
+		g.Score = score
+		g.Turn = 12
+		g.Stats.MaxChain = 3
+		m.Save(g)
+		g.End(GameOver)
+		if g.Rank != -1 {
+			t.Error("daily ranked", g.Rank)
+		}
+	}
+	r, ok := LoadDaily(m.Date)
+	if !ok || r.Score != 100 {
+		t.Error("first attempt not kept", r, ok)
+	}
+	if _, ok := LoadDaily("2026-10-20"); ok {
+		t.Error("other day played")
+	}
+	practice := Daily{Date: m.Date, Practice: true}
+	g.Start(practice)
+	g.Score = 300
+	practice.Save(g)
+	if r, _ := LoadDaily(m.Date); r.Score != 100 {
+		t.Error("practice recorded", r)
+	}
+	want := "cut'n'align daily 2026-10-19\nscore 100\nturns 12\nmax chain 3\n"
+	if s := r.ShareText(); s != want {
+		t.Error("share", s, want)
+	}
+	if records := LoadRecords(Endless{}); len(records) != 0 {
+		t.Error("daily ranked as endless", records)
+	}
+}
+
+func TestDailyQuit(t *testing.T) {
+	defer func(s Storage) { DefaultStorage = s }(DefaultStorage)
+	DefaultStorage = MemoryStorage{}
+
+	m := Daily{Date: "2026-10-19"}
+	g := NewGame()
+	g.Start(m)
+	g.Score = 50
+	// quit without ending the game
+	r, ok := LoadDaily(m.Date)
+	if !ok || r.Finished {
+		t.Error("attempt not saved at start", r, ok)
+	}
+	if item := DailyMenu(m.Date).Items[0]; item.Label == T("Play") {
+		t.Error("playable again")
+	}
+	// starting again is practice
+	g.Start(m)
+	g.Score = 200
+	g.Mode.(Daily).Save(g)
+	if r, _ := LoadDaily(m.Date); r.Score != 0 || r.Finished {
+		t.Error("second attempt recorded", r)
+	}
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+const (
+	// brushes are lined up right of the board
+	BrushX = 150
+	BrushY = 50
+
+	// pick sequence is above the board
+	EditPickX   = 10
+	EditPickY   = 2
+	EditPickMax = 8
+
+	EditButtonX      = 146
+	EditButtonY      = 200
+	EditButtonHeight = 16
+)
+
+var Brushes []Color = []Color{None, Red, Blue, Green, Yellow, Pink, Orange, Wall, Jammer}
+
+var EditGoals []Goal = []Goal{
+	{},
+	{Kind: ClearBoard},
+	{Kind: ClearJammers},
+	{Kind: MakeChain, N: 2},
+	{Kind: MakeChain, N: 3},
+	{Kind: MakeChain, N: 4},
+}
+
+// Editor paints a stage on the board.
+type Editor struct {
+	Board *Board
+	Brush Color
+	Pick  []Color
+	Goal  int
+	Cuts  int
+	// shown at the bottom, e.g. why the stage can not be played
+	Message string
+	// presses started before the editor opened do not paint
+	opened int
+}
+
+func NewEditor() *Editor {
+	e := &Editor{
+		Board: NewBoard(),
+		Brush: Red,
+	}
+	e.Board.Initialize()
+	e.Place()
+	return e
+}
+
+// Place keeps the board where it stands in the portrait frame, the controls are laid out around it.
+func (e *Editor) Place() {
+	e.Board.OriginX = Viewport.Left() + 10
+	e.Board.OriginY = Viewport.Top() + ScreenHeight - 10 - StoneHeight*BoardHeight
+}
+
+// Paint sets the brush on a playfield cell.
+func (e *Editor) Paint(cx, cy int) {
+	if !InPlayfield(cx, cy) {
+		return
+	}
+	if c, ok := e.Board.At(cx, cy); ok {
+		if e.Brush == None {
+			*c = nil
+		} else {
+			*c = &Stone{Color: e.Brush}
+		}
+	}
+}
+
+// PaintPick appends the brush to the pick, or removes the i-th stone with the eraser.
+func (e *Editor) PaintPick(i int) {
+	if e.Brush == None {
+		if 0 <= i && i < len(e.Pick) {
+			e.Pick = append(e.Pick[:i], e.Pick[i+1:]...)
+		}
+		return
+	}
+	if e.Brush.Colored() && len(e.Pick) < EditPickMax {
+		e.Pick = append(e.Pick, e.Brush)
+	}
+}
+
+func (e *Editor) Stage() *Stage {
+	s := &Stage{
+		Name: "Edit",
+		Pick: append([]Color{}, e.Pick...),
+		Goal: EditGoals[e.Goal],
+		Cuts: e.Cuts,
+	}
+	used := map[Color]bool{}
+	for _, c := range e.Pick {
+		used[c] = true
+	}
+	for cy := 1; cy < BoardHeight-1; cy++ {
+		for cx := 1; cx < BoardWidth-1; cx++ {
+			if c := e.Board.ColorAt(cx, cy); c != None {
+				s.Cells = append(s.Cells, StageCell{cx, cy, c})
+				used[c] = true
+			}
+		}
+	}
+	for _, c := range []Color{Red, Blue, Green, Yellow, Pink, Orange} {
+		if used[c] {
+			s.Palette = append(s.Palette, c)
+		}
+	}
+	if len(s.Palette) == 0 {
+		s.Palette = []Color{Red, Blue, Green}
+	}
+	return s
+}
+
+func (g *Game) OpenEditor(e *Editor) {
+	g.Editor = e
+	e.opened = g.Ticks
+	g.Step = Edit
+}
+
+func (e *Editor) Play(g *Game) {
+	s := e.Stage()
+	if err := s.Validate(); err != nil {
+		e.Message = err.Error()
+		return
+	}
+	e.Message = ""
+	g.Start(NewPuzzleMode(s))
+	g.Back = func(g *Game) { g.OpenEditor(e) }
+}
+
+func (e *Editor) Export() {
+	name := "stage.txt"
+	if err := ExportText(name, e.Stage().Format()); err != nil {
+		e.Message = err.Error()
+		return
+	}
+	e.Message = Tf("exported %s", name)
+}
+
+var EditButtons []MenuItem = []MenuItem{
+	{"GOAL", func(g *Game) { g.Editor.Goal = (g.Editor.Goal + 1) % len(EditGoals) }},
+	{"CUTS", func(g *Game) { g.Editor.Cuts = (g.Editor.Cuts + 1) % 6 }},
+	{"PLAY", func(g *Game) { g.Editor.Play(g) }},
+	{"SAVE", func(g *Game) { g.Editor.Export() }},
+	{"BACK", func(g *Game) { g.OpenMenu(MainMenu()) }},
+}
+
+func (e *Editor) Update(g *Game) {
+	e.Place()
+	// paint while pressed
+	var points []Point
+	since := g.Ticks - e.opened
+	if g.MouseEnabled && ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) && inpututil.MouseButtonPressDuration(ebiten.MouseButtonLeft) <= since {
+		x, y := CursorPosition()
+		points = append(points, Point{x, y})
+	}
+	for _, tid := range ebiten.TouchIDs() {
+		if inpututil.TouchPressDuration(tid) <= since {
+			x, y := TouchPosition(tid)
+			points = append(points, Point{x, y})
+		}
+	}
+	for _, p := range points {
+		e.Paint(e.Board.PosToCell(p.x, p.y))
+	}
+
+	if p, ok := JustPressed(); ok {
+		e.Press(g, p.x, p.y)
+	}
+}
+
+// Press picks the brush, paints the pick or pushes the button at x, y.
+func (e *Editor) Press(g *Game, x, y int) {
+	p := Point{x - Viewport.Left(), y - Viewport.Top()}
+	if p.x >= BrushX && p.y >= BrushY {
+		if i := (p.y - BrushY) / StoneHeight; i < len(Brushes) {
+			e.Brush = Brushes[i]
+			return
+		}
+	}
+	if p.y >= EditPickY && p.y < EditPickY+StoneHeight && p.x >= EditPickX && p.x < EditPickX+EditPickMax*StoneWidth {
+		e.PaintPick((p.x - EditPickX) / StoneWidth)
+		return
+	}
+	if p.x >= EditButtonX && p.y >= EditButtonY {
+		if i := (p.y - EditButtonY) / EditButtonHeight; i < len(EditButtons) {
+			EditButtons[i].Action(g)
+		}
+	}
+}
+
+func renderStoneAt(r *ebiten.Image, c Color, x, y int) {
+	opt := SpriteOptions()
+	opt.GeoM.Translate(float64(x), float64(y))
+	if c.Colored() {
+		ApplySkin(opt)
+	}
+	if image, ok := StoneImages[c]; ok {
+		Blit(r, image, opt)
+	}
+}
+
+func (e *Editor) Draw(r *ebiten.Image) {
+	left, top := Viewport.Left(), Viewport.Top()
+	e.Board.Render(r, 0, 0)
+	for i, c := range Brushes {
+		renderStoneAt(r, c, left+BrushX, top+BrushY+i*StoneHeight)
+		if c == e.Brush {
+			renderStoneAt(r, Cursor, left+BrushX, top+BrushY+i*StoneHeight)
+		}
+	}
+	for i := 0; i < EditPickMax; i++ {
+		c := None
+		if i < len(e.Pick) {
+			c = e.Pick[i]
+		}
+		renderStoneAt(r, c, left+EditPickX+i*StoneWidth, top+EditPickY)
+	}
+	for i, b := range EditButtons {
+		DrawText(r, T(b.Label), left+EditButtonX, top+EditButtonY+i*EditButtonHeight)
+	}
+	goal := EditGoals[e.Goal].String()
+	if goal == "" {
+		goal = T("NO GOAL")
+	}
+	goal = strings.Replace(goal, " ", "\n", -1)
+	DrawText(r, goal+"\n"+Tf("CUTS %d", e.Cuts), left+EditPickX+EditPickMax*StoneWidth+2, top)
+	if e.Message != "" {
+		DrawText(r, strings.SplitN(e.Message, "\n", 2)[0], left+2, top+ScreenHeight-16)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEditorStage(t *testing.T) {
+	e := NewEditor()
+	e.Brush = Red
+	e.Paint(1, 14)
+	e.Paint(2, 14)
+	e.Paint(0, 14) // side wall is not paintable
+	e.Brush = Wall
+	e.Paint(3, 14)
+	e.Brush = Jammer
+	e.Paint(3, 13)
+	e.Brush = Blue
+	e.PaintPick(0)
+	e.PaintPick(0)
+	e.Brush = Red
+	e.PaintPick(0)
+	e.Brush = None
+	e.PaintPick(1)
+	e.Paint(2, 14)
+	e.Goal = 2
+
+	s := e.Stage()
+	if err := s.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	want := &Stage{
+		Name:    "Edit",
+		Palette: []Color{Red, Blue},
+		Pick:    []Color{Blue, Red},
+		Goal:    Goal{Kind: ClearJammers},
+		Cells: []StageCell{
+			{3, 13, Jammer},
+			{1, 14, Red},
+			{3, 14, Wall},
+		},
+	}
+	if !reflect.DeepEqual(s, want) {
+		t.Error(s, want)
+	}
+	if e.Board.ColorAt(0, 14) != Wall {
+		t.Error("side wall painted")
+	}
+}
+
+func TestEditorPlayBack(t *testing.T) {
+	defer func(s Storage) { DefaultStorage = s }(DefaultStorage)
+	DefaultStorage = MemoryStorage{}
+
+	g := NewGame()
+	e := NewEditor()
+	e.Brush = Red
+	e.PaintPick(0)
+	g.OpenEditor(e)
+	e.Play(g)
+	if g.Step != Move {
+		t.Fatal("not played", e.Message)
+	}
+	if _, quit := g.Mode.Expired(g); quit {
+		t.Error("quit without a press")
+	}
+	// as quit
+	g.End(GameOver)
+	g.Back(g)
+	if g.Step != Edit || g.Editor != e {
+		t.Error("editor not back", g.Step)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+// Event is one of the gameplay events below, sent through Game.Emit.
+type Event interface{}
+
+// CutPlaced is sent when the pick is cut into column X, its bottom stone at row Y.
+type CutPlaced struct {
+	X, Y   int
+	Stones []*Stone
+}
+
+// StonesMatched is sent for every erase step, Chain counting from 1.
+type StonesMatched struct {
+	Match    MatchResult
+	Chain    int
+	AllClear bool
+}
+
+// ChainAdvanced is sent after StonesMatched when the chain count goes up.
+type ChainAdvanced struct {
+	Chain int
+}
+
+// StonesLanded is sent when falling stones come to rest.
+type StonesLanded struct{}
+
+type JammerDropped struct {
+	Points []Point
+}
+
+// TurnEnded is sent after the mode decided the Next step.
+type TurnEnded struct {
+	Turn int
+	Next Step
+}
+
+// GameEnded is sent when a game ends at GameOver or Clear.
+type GameEnded struct {
+	Step Step
+}
+
+type Handler func(g *Game, e Event)
+
+// Bus calls handlers in the order they subscribed.
+type Bus struct {
+	handlers []Handler
+}
+
+func (b *Bus) Subscribe(hs ...Handler) {
+	b.handlers = append(b.handlers, hs...)
+}
+
+func (b *Bus) Emit(g *Game, e Event) {
+	for _, h := range b.handlers {
+		h(g, e)
+	}
+}
+
+func (g *Game) Emit(e Event) {
+	g.Events.Emit(g, e)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGameEvents(t *testing.T) {
+	defer func(s Storage) { DefaultStorage = s }(DefaultStorage)
+	DefaultStorage = MemoryStorage{}
+
+	type Case struct {
+		t      string
+		cells  []StageCell
+		pick   []Color
+		x      int
+		events []Event
+	}
+	cases := []Case{
+		Case{
+			"no match",
+			nil,
+			[]Color{Red},
+			1,
+			[]Event{
+				CutPlaced{1, PickMax - 1, nil},
+				StonesLanded{},
+				TurnEnded{1, GameOver},
+				GameEnded{GameOver},
+			},
+		},
+		Case{
+			"match",
+			[]StageCell{{1, 14, Red}, {2, 14, Red}, {4, 14, Blue}},
+			[]Color{Red, Blue},
+			3,
+			[]Event{
+				CutPlaced{3, PickMax - 1, nil},
+				StonesLanded{},
+				StonesMatched{},
+				ChainAdvanced{1},
+				TurnEnded{1, Move},
+			},
+		},
+	}
+	for _, cs := range cases {
+		g := NewGame()
+		p := &Stage{Name: cs.t, Cells: cs.cells, Pick: cs.pick}
+		g.Start(NewPuzzleMode(p))
+		var events []Event
+		g.Events.Subscribe(func(g *Game, e Event) {
+			// only the types and chains are compared
+			switch e := e.(type) {
+			case CutPlaced:
+				e.Stones = nil
+				events = append(events, e)
+			case StonesMatched:
+				events = append(events, StonesMatched{})
+			default:
+				events = append(events, e)
+			}
+		})
+		g.AdjustPick(cs.x, g.PickY)
+		g.FixPick()
+		for i := 0; i < 100 && g.Step != Move && g.Step != Clear && g.Step != GameOver; i++ {
+			g.Update()
+		}
+		if !reflect.DeepEqual(events, cs.events) {
+			t.Error(cs.t, events, cs.events)
+		}
+	}
+}
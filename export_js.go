@@ -0,0 +1,36 @@
+//go:build js
+// +build js
+
+package main
+
+import (
+	"errors"
+	"syscall/js"
+)
+
+// ExportText lets the browser download text as a file.
+func ExportText(name, text string) error {
+	doc := js.Global().Get("document")
+	if doc.IsUndefined() {
+		return errors.New("no document to export to")
+	}
+	url := js.Global().Get("URL")
+	blob := js.Global().Get("Blob").New([]interface{}{text}, map[string]interface{}{"type": "text/plain"})
+	href := url.Call("createObjectURL", blob)
+	a := doc.Call("createElement", "a")
+	a.Set("href", href)
+	a.Set("download", name)
+	a.Call("click")
+	url.Call("revokeObjectURL", href)
+	return nil
+}
+
+// ShareText copies text to the clipboard, or downloads it without clipboard access.
+func ShareText(text string) error {
+	nav := js.Global().Get("navigator")
+	if nav.IsUndefined() || nav.Get("clipboard").IsUndefined() {
+		return ExportText("share.txt", text)
+	}
+	nav.Get("clipboard").Call("writeText", text)
+	return nil
+}
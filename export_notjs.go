@@ -0,0 +1,18 @@
+//go:build !js
+// +build !js
+
+package main
+
+import (
+	"io/ioutil"
+)
+
+// ExportText writes text to a file in the working directory.
+func ExportText(name, text string) error {
+	return ioutil.WriteFile(name, []byte(text), 0644)
+}
+
+// ShareText writes text to share.txt, there is no clipboard to rely on.
+func ShareText(text string) error {
+	return ExportText("share.txt", text)
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+type Align int
+
+const (
+	AlignLeft Align = iota
+	AlignCenter
+	AlignRight
+)
+
+// Glyph is one cell of a glyph sheet, Width being the inked columns.
+type Glyph struct {
+	Image *ebiten.Image
+	Width int
+}
+
+// Font draws text from a glyph sheet, one line every LineHeight pixels.
+// Runes not on the sheet are taken from Face, e.g. kana and kanji.
+type Font struct {
+	Glyphs     map[rune]*Glyph
+	Missing    *Glyph
+	LineHeight int
+	// pixels between glyphs
+	Spacing int
+	Face    font.Face
+	// baseline of Face glyphs from the top of the line
+	FaceBaseline int
+}
+
+var DefaultFont *Font
+
+// NewFont cuts sheet into cw x ch cells in cols columns, runes counting up from first.
+// The last cell is drawn for runes not on the sheet.
+// Empty cells are spaces of half the cell width.
+func NewFont(sheet image.Image, cw, ch, cols int, first rune) *Font {
+	f := &Font{
+		Glyphs:     map[rune]*Glyph{},
+		LineHeight: ch,
+		Spacing:    1,
+	}
+	img := ebiten.NewImageFromImage(sheet)
+	b := sheet.Bounds()
+	n := (b.Dx() / cw) * (b.Dy() / ch)
+	for i := 0; i < n; i++ {
+		rect := image.Rect(i%cols*cw, i/cols*ch, i%cols*cw+cw, i/cols*ch+ch).Add(b.Min)
+		width := 0
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			for y := rect.Min.Y; y < rect.Max.Y; y++ {
+				if _, _, _, a := sheet.At(x, y).RGBA(); a > 0 {
+					width = x - rect.Min.X + 1
+				}
+			}
+		}
+		if width == 0 {
+			width = cw / 2
+		}
+		g := &Glyph{img.SubImage(rect).(*ebiten.Image), width}
+		f.Glyphs[first+rune(i)] = g
+		f.Missing = g
+	}
+	return f
+}
+
+// Glyph returns the glyph of c, the missing glyph when neither the sheet nor Face has one.
+func (f *Font) Glyph(c rune) *Glyph {
+	if g, ok := f.Glyphs[c]; ok {
+		return g
+	}
+	if f.Face == nil {
+		return f.Missing
+	}
+	advance, ok := f.Face.GlyphAdvance(c)
+	if !ok || advance.Ceil() == 0 {
+		return f.Missing
+	}
+	img := image.NewNRGBA(image.Rect(0, 0, advance.Ceil(), f.LineHeight))
+	d := &font.Drawer{Dst: img, Src: image.White, Face: f.Face, Dot: fixed.P(0, f.FaceBaseline)}
+	d.DrawString(string(c))
+	// Face glyphs have their spacing in the advance
+	g := &Glyph{ebiten.NewImageFromImage(img), advance.Ceil() - f.Spacing}
+	f.Glyphs[c] = g
+	return g
+}
+
+// LineWidth measures one line in pixels.
+func (f *Font) LineWidth(line string) int {
+	w := 0
+	for _, c := range line {
+		w += f.Glyph(c).Width + f.Spacing
+	}
+	if w > 0 {
+		w -= f.Spacing
+	}
+	return w
+}
+
+// Measure returns the size of text, lines separated by '\n'.
+func (f *Font) Measure(text string) (int, int) {
+	lines := strings.Split(text, "\n")
+	w := 0
+	for _, l := range lines {
+		w = maxInt(w, f.LineWidth(l))
+	}
+	return w, len(lines) * f.LineHeight
+}
+
+// Wrap breaks text into lines not wider than width, between words when it can.
+func (f *Font) Wrap(text string, width int) []string {
+	var lines []string
+	for _, para := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(para) {
+			next := word
+			if line != "" {
+				next = line + " " + word
+			}
+			if f.LineWidth(next) <= width {
+				line = next
+				continue
+			}
+			if line != "" {
+				lines = append(lines, line)
+			}
+			// a word longer than the width is cut anywhere
+			for f.LineWidth(word) > width && utf8.RuneCountInString(word) > 1 {
+				i := 0
+				for j := range word {
+					if j > 0 && f.LineWidth(word[:j]) > width {
+						break
+					}
+					i = j
+				}
+				if i == 0 {
+					_, i = utf8.DecodeRuneInString(word)
+				}
+				lines = append(lines, word[:i])
+				word = word[i:]
+			}
+			line = word
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// Draw draws text with a shadow, x being the left, center or right by align and y the top.
+func (f *Font) Draw(r *ebiten.Image, text string, x, y int, align Align, clr color.Color) {
+	c := color.NRGBAModel.Convert(clr).(color.NRGBA)
+	cr, cg, cb, ca := float64(c.R)/0xff, float64(c.G)/0xff, float64(c.B)/0xff, float64(c.A)/0xff
+	for i, line := range strings.Split(text, "\n") {
+		lx := x
+		switch align {
+		case AlignCenter:
+			lx -= f.LineWidth(line) / 2
+		case AlignRight:
+			lx -= f.LineWidth(line)
+		}
+		ly := y + i*f.LineHeight
+		for _, c := range line {
+			g := f.Glyph(c)
+			opt := &ebiten.DrawImageOptions{Filter: ebiten.FilterNearest}
+			opt.GeoM.Translate(float64(lx+1), float64(ly+1))
+			opt.ColorM.Scale(0, 0, 0, ca)
+			Blit(r, g.Image, opt)
+			opt = &ebiten.DrawImageOptions{Filter: ebiten.FilterNearest}
+			opt.GeoM.Translate(float64(lx), float64(ly))
+			opt.ColorM.Scale(cr, cg, cb, ca)
+			Blit(r, g.Image, opt)
+			lx += g.Width + f.Spacing
+		}
+	}
+}
+
+// DrawText draws white text of the default font, x, y being left top.
+func DrawText(r *ebiten.Image, text string, x, y int) {
+	DefaultFont.Draw(r, text, x, y, AlignLeft, color.White)
+}
+
+// DrawTextAligned is DrawText aligned at x.
+func DrawTextAligned(r *ebiten.Image, text string, x, y int, align Align) {
+	DefaultFont.Draw(r, text, x, y, align, color.White)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+// testFont has ' ' to '_' in 8 columns, 'A' 3 pixels wide, 'B' 5 pixels wide
+// and the missing glyph '_' 2 pixels wide.
+func testFont() *Font {
+	sheet := image.NewRGBA(image.Rect(0, 0, 8*8, 8*16))
+	for c, w := range map[rune]int{'A': 3, 'B': 5, '_': 2} {
+		i := int(c - ' ')
+		for x := 0; x < w; x++ {
+			sheet.Set(i%8*8+x, i/8*16+8, color.White)
+		}
+	}
+	return NewFont(sheet, 8, 16, 8, ' ')
+}
+
+func TestFontMeasure(t *testing.T) {
+	f := testFont()
+	type Case struct {
+		t    string
+		text string
+		w, h int
+	}
+	cases := []Case{
+		Case{"empty", "", 0, 16},
+		Case{"one", "A", 3, 16},
+		Case{"spacing", "AB", 3 + 1 + 5, 16},
+		Case{"space", "A A", 3 + 1 + 4 + 1 + 3, 16},
+		Case{"missing", "Ax", 3 + 1 + 2, 16},
+		Case{"lines", "A\nBB", 5 + 1 + 5, 32},
+	}
+	for _, cs := range cases {
+		w, h := f.Measure(cs.text)
+		if w != cs.w || h != cs.h {
+			t.Error(cs.t, w, h, cs.w, cs.h)
+		}
+	}
+	if f.Glyph('z') != f.Missing || f.Glyph('A') == f.Missing {
+		t.Error("missing glyph")
+	}
+}
+
+func TestFontWrap(t *testing.T) {
+	f := testFont()
+	type Case struct {
+		t     string
+		text  string
+		width int
+		lines []string
+	}
+	cases := []Case{
+		Case{"fits", "A A", 12, []string{"A A"}},
+		Case{"words", "A A A", 12, []string{"A A", "A"}},
+		Case{"newline", "A\nA", 100, []string{"A", "A"}},
+		Case{"long word", "BBBB", 11, []string{"BB", "BB"}},
+		Case{"narrower than a glyph", "BB", 2, []string{"B", "B"}},
+		Case{"spaces", "  A   B ", 100, []string{"A B"}},
+	}
+	for _, cs := range cases {
+		if lines := f.Wrap(cs.text, cs.width); !reflect.DeepEqual(lines, cs.lines) {
+			t.Error(cs.t, lines, cs.lines)
+		}
+	}
+}
+
+func TestDefaultFont(t *testing.T) {
+	for c := ' '; c <= '~'; c++ {
+		if f := DefaultFont.Glyph(c); f == DefaultFont.Missing {
+			t.Errorf("%q missing", c)
+		}
+	}
+	for _, c := range "カット連鎖" {
+		if g := DefaultFont.Glyph(c); g == DefaultFont.Missing || g.Width < 8 {
+			t.Errorf("%q missing", c)
+		}
+	}
+	if DefaultFont.Glyph('\U0001F600') != DefaultFont.Missing {
+		t.Error("no missing glyph")
+	}
+}
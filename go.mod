@@ -2,4 +2,8 @@ module github.com/neguse/ld44
 
 go 1.16
 
-require github.com/hajimehoshi/ebiten/v2 v2.1.0
+require (
+	github.com/hajimehoshi/bitmapfont/v2 v2.1.3
+	github.com/hajimehoshi/ebiten/v2 v2.1.0
+	golang.org/x/image v0.0.0-20210220032944-ac19c3e999fb
+)
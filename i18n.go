@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Lang string
+
+const (
+	English  Lang = "en"
+	Japanese Lang = "ja"
+)
+
+var Langs []Lang = []Lang{English, Japanese}
+
+// LangNames are written in their own language.
+var LangNames map[Lang]string = map[Lang]string{
+	English:  "English",
+	Japanese: "日本語",
+}
+
+var CurrentLang Lang = English
+
+// Translations map English text to other languages,
+// text missing from a table is shown in English.
+var Translations map[Lang]map[string]string = map[Lang]map[string]string{
+	Japanese: {
+		// title and menus
+		"click to cut": "クリックでカット",
+		"Very thanks to\n@hajimehoshi\nand my brother.": "感謝:\n@hajimehoshi\nと兄弟",
+		"Tutorial":              "チュートリアル",
+		"Endless":               "エンドレス",
+		"Zen":                   "禅",
+		"Daily":                 "デイリー",
+		"Attack":                "アタック",
+		"Puzzle":                "パズル",
+		"Editor":                "エディタ",
+		"Records":               "記録",
+		"Stats":                 "統計",
+		"Achievements":          "実績",
+		"Settings":              "設定",
+		"Back":                  "戻る",
+		"Play":                  "プレイ",
+		"Practice":              "練習",
+		"Share":                 "共有",
+		"no record":             "記録なし",
+		"Score 30 turns":        "30ターン スコア",
+		"Score 3 min":           "3分 スコア",
+		"Clear 100":             "100個 消し",
+		"Daily %s":              "デイリー %s",
+		"score %d":              "スコア %d",
+		"turns %d  chain %d":    "%dターン  %d連鎖",
+		"Stats  %d games":       "統計  %dゲーム",
+		"Skin: %s":              "スキン: %s",
+		"BGM: %s":               "BGM: %s",
+		"Language: %s":          "言語: %s",
+		"On":                    "オン",
+		"Off":                   "オフ",
+		"BGM":                   "BGM",
+		"SFX":                   "効果音",
+		"Mute: %s":              "ミュート: %s",
+		"Hand: Right":           "利き手: 右",
+		"Hand: Left":            "利き手: 左",
+		"Reduce motion: %s":     "動きをへらす: %s",
+		"Shapes: %s":            "形: %s",
+		"High DPI: %s":          "高解像度: %s",
+		"Theme: %s":             "テーマ: %s",
+		"Palette: %s":           "配色: %s",
+		"Classic":               "クラシック",
+		"Deuteranopia":          "2型色覚",
+		"Protanopia":            "1型色覚",
+		"Tritanopia":            "3型色覚",
+		"Display":               "表示",
+		"Keys":                  "キー設定",
+		"Reset keys":            "キーを元に戻す",
+		"Press a key: %s":       "キーを押す: %s",
+		"Cut more":              "多くカット",
+		"Cut less":              "少なくカット",
+		"Cut":                   "カット",
+		"Quit":                  "やめる",
+		"Left":                  "左",
+		"Right":                 "右",
+		"UNLOCKED %s":           "解除 %s",
+		"3-chain":               "3連鎖",
+		"5-chain":               "5連鎖",
+		"10 jammers in a game":  "1ゲームでおじゃま10個",
+		"turn 72 in six colors": "6色で72ターン",
+		"four ways at once":     "4方向 同時消し",
+		"clear the board":       "全消し",
+
+		// tutorial
+		"Cut 1 stone in column 3 to line up 3.":     "3列目で1個カットして\n3つ並べよう。",
+		"Point higher for more: cut 2 in column 2.": "上を指すほど多くカット。\n2列目に2個カットしよう。",
+		"Diagonals count. Cut 1 in column 3.":       "ななめも数えるよ。\n3列目に1個カットしよう。",
+		"Falling stones chain. Cut 2 in column 3.":  "落ちた石で連鎖。\n3列目に2個カットしよう。",
+		"Well done! Tap to go on.":                  "よくできました! タップで次へ。",
+		"Tap to try again.":                         "タップでもう一度。",
+
+		// play and results
+		"HIGH SCORE":       "ハイスコア",
+		"SCORE":            "スコア",
+		"CHAIN":            "連鎖",
+		"LEVEL %d":         "レベル %d",
+		"NEXT":             "つぎ",
+		"TIME":             "のこり秒",
+		"TURNS":            "のこりターン",
+		"STONES":           "のこり石",
+		"LOADING":          "読み込み中",
+		"NO SOUND":         "音が出せません",
+		"QUIT":             "やめる",
+		"DAILY %s":         "デイリー %s",
+		"CLEAR JAMMERS":    "おじゃまを消す",
+		"%d-CHAIN":         "%d連鎖",
+		"CLEAR ALL":        "全消し",
+		"CUTS %d":          "のこり %dカット",
+		"CLEAR!":           "クリア!",
+		"RANK %d":          "%d位",
+		"TOPPED OUT":       "いっぱい!",
+		"CUTS %d AVG %.1f": "カット %d 平均 %.1f",
+		"PLACED %d":        "置いた石 %d",
+		"JAMMERS +%d -%d":  "おじゃま +%d -%d",
+		"MAX CHAIN %d":     "最大連鎖 %d",
+		"BEST STEP %d":     "最高得点 %d",
+		"COLORS %s":        "色数 %s",
+
+		// editor
+		"GOAL":        "ゴール",
+		"CUTS":        "カット",
+		"PLAY":        "プレイ",
+		"SAVE":        "保存",
+		"BACK":        "戻る",
+		"NO GOAL":     "ゴールなし",
+		"exported %s": "%s に書き出した",
+	},
+}
+
+// T translates text to the current language.
+func T(text string) string {
+	if t, ok := Translations[CurrentLang][text]; ok {
+		return t
+	}
+	return text
+}
+
+// Tf formats the translated format.
+func Tf(format string, a ...interface{}) string {
+	return fmt.Sprintf(T(format), a...)
+}
+
+// ParseLocale picks the language of a locale like "ja-JP" or "ja_JP.UTF-8".
+func ParseLocale(locale string) Lang {
+	locale = strings.ToLower(locale)
+	for _, l := range Langs {
+		if locale == string(l) || strings.HasPrefix(locale, string(l)+"-") || strings.HasPrefix(locale, string(l)+"_") {
+			return l
+		}
+	}
+	return English
+}
+
+func DetectLang() Lang {
+	return ParseLocale(SystemLocale())
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestParseLocale(t *testing.T) {
+	type Case struct {
+		t      string
+		locale string
+		lang   Lang
+	}
+	cases := []Case{
+		Case{"empty", "", English},
+		Case{"english", "en-US", English},
+		Case{"japanese", "ja", Japanese},
+		Case{"browser", "ja-JP", Japanese},
+		Case{"posix", "ja_JP.UTF-8", Japanese},
+		Case{"upper", "JA-JP", Japanese},
+		Case{"other", "fr-FR", English},
+		Case{"prefix only", "jav", English},
+	}
+	for _, cs := range cases {
+		if l := ParseLocale(cs.locale); l != cs.lang {
+			t.Error(cs.t, l, cs.lang)
+		}
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	defer func(l Lang) { CurrentLang = l }(CurrentLang)
+	CurrentLang = English
+	if s := T("Back"); s != "Back" {
+		t.Error("english", s)
+	}
+	if s := Tf("RANK %d", 2); s != "RANK 2" {
+		t.Error("english format", s)
+	}
+	CurrentLang = Japanese
+	if s := T("Back"); s != "戻る" {
+		t.Error("japanese", s)
+	}
+	if s := Tf("RANK %d", 2); s != "2位" {
+		t.Error("japanese format", s)
+	}
+	if s := T("not translated"); s != "not translated" {
+		t.Error("fallback", s)
+	}
+}
+
+func TestTranslationsHaveGlyphs(t *testing.T) {
+	for lang, table := range Translations {
+		for en, s := range table {
+			for _, c := range s {
+				if c == '\n' {
+					continue
+				}
+				if g := DefaultFont.Glyph(c); g == DefaultFont.Missing {
+					t.Error(lang, en, string(c))
+				}
+			}
+		}
+	}
+}
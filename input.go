@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// Action is a thing done by keys during play.
+type Action string
+
+const (
+	ActLeft  Action = "left"
+	ActRight Action = "right"
+	// cut one stone more or less
+	ActMore Action = "more"
+	ActLess Action = "less"
+	ActCut  Action = "cut"
+	ActQuit Action = "quit"
+)
+
+var Actions []Action = []Action{ActLeft, ActRight, ActMore, ActLess, ActCut, ActQuit}
+
+var ActionNames map[Action]string = map[Action]string{
+	ActLeft:  "Left",
+	ActRight: "Right",
+	ActMore:  "Cut more",
+	ActLess:  "Cut less",
+	ActCut:   "Cut",
+	ActQuit:  "Quit",
+}
+
+// Keys are saved by name so that they survive renumbering in ebiten.
+type Keys []ebiten.Key
+
+func (ks Keys) MarshalJSON() ([]byte, error) {
+	names := []string{}
+	for _, k := range ks {
+		names = append(names, k.String())
+	}
+	return json.Marshal(names)
+}
+
+func (ks *Keys) UnmarshalJSON(data []byte) error {
+	var names []string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return err
+	}
+	*ks = nil
+	for _, name := range names {
+		if k, ok := KeyByName(name); ok {
+			*ks = append(*ks, k)
+		}
+	}
+	return nil
+}
+
+func KeyByName(name string) (ebiten.Key, bool) {
+	for k := ebiten.Key(0); k <= ebiten.KeyMax; k++ {
+		if k.String() == name {
+			return k, true
+		}
+	}
+	return 0, false
+}
+
+var DefaultBindings map[Action]Keys = map[Action]Keys{
+	ActLeft:  {ebiten.KeyLeft},
+	ActRight: {ebiten.KeyRight},
+	ActMore:  {ebiten.KeyUp},
+	ActLess:  {ebiten.KeyDown},
+	ActCut:   {ebiten.KeyEnter, ebiten.KeySpace},
+	ActQuit:  {ebiten.KeyEscape},
+}
+
+// Keys returns the keys bound to a, the default ones unless rebound.
+func (s Settings) Keys(a Action) Keys {
+	if ks := s.Bindings[a]; len(ks) > 0 {
+		return ks
+	}
+	return DefaultBindings[a]
+}
+
+func ActionJustPressed(a Action) bool {
+	return KeyJustPressed(Setting.Keys(a)...)
+}
+
+// JustPressedKey returns a key pressed in this frame.
+func JustPressedKey() (ebiten.Key, bool) {
+	for k := ebiten.Key(0); k <= ebiten.KeyMax; k++ {
+		if inpututil.IsKeyJustPressed(k) {
+			return k, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+)
+
+const (
+	// the logical screen grows up to these along the longer side of the window
+	MaxViewWidth  = 480
+	MaxViewHeight = 450
+)
+
+// View is the logical screen, ScreenWidth x ScreenHeight at the least
+// and stretched to the aspect ratio of the window instead of letterboxed.
+type View struct {
+	Width, Height int
+	// device pixels to a logical pixel, a whole number to keep the pixel art sharp
+	Scale float64
+}
+
+var Viewport View = View{ScreenWidth, ScreenHeight, 1}
+
+// NewView fits the logical screen to a window of w x h, on a display of deviceScale.
+func NewView(w, h int, deviceScale float64) View {
+	v := View{ScreenWidth, ScreenHeight, 1}
+	if w <= 0 || h <= 0 {
+		return v
+	}
+	if w*ScreenHeight > h*ScreenWidth {
+		v.Width = minInt(ScreenHeight*w/h, MaxViewWidth)
+	} else {
+		v.Height = minInt(ScreenWidth*h/w, MaxViewHeight)
+	}
+	if s := math.Floor(deviceScale); s > 1 {
+		v.Scale = s
+	}
+	return v
+}
+
+// Left and Top place the ScreenWidth x ScreenHeight frame the screens are designed in,
+// centered and at the bottom where the board stands.
+func (v View) Left() int {
+	return (v.Width - ScreenWidth) / 2
+}
+
+func (v View) Top() int {
+	return v.Height - ScreenHeight
+}
+
+// Blit draws img in logical pixels, scaled to the device pixels of the view.
+func Blit(r, img *ebiten.Image, opt *ebiten.DrawImageOptions) {
+	opt.GeoM.Scale(Viewport.Scale, Viewport.Scale)
+	r.DrawImage(img, opt)
+}
+
+func FillRect(r *ebiten.Image, x, y, w, h float64, clr color.Color) {
+	s := Viewport.Scale
+	ebitenutil.DrawRect(r, x*s, y*s, w*s, h*s, clr)
+}
+
+// CursorPosition and TouchPosition are in logical pixels.
+func CursorPosition() (int, int) {
+	x, y := ebiten.CursorPosition()
+	return int(float64(x) / Viewport.Scale), int(float64(y) / Viewport.Scale)
+}
+
+func TouchPosition(id ebiten.TouchID) (int, int) {
+	x, y := ebiten.TouchPosition(id)
+	return int(float64(x) / Viewport.Scale), int(float64(y) / Viewport.Scale)
+}
+
+func (g *Game) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
+	scale := 1.0
+	if Setting.HiDPI {
+		scale = ebiten.DeviceScaleFactor()
+	}
+	Viewport = NewView(outsideWidth, outsideHeight, scale)
+	return int(float64(Viewport.Width) * Viewport.Scale), int(float64(Viewport.Height) * Viewport.Scale)
+}
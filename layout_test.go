@@ -0,0 +1,114 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestNewView(t *testing.T) {
+	type Case struct {
+		t     string
+		w, h  int
+		scale float64
+		view  View
+	}
+	cases := []Case{
+		Case{"design", 400, 600, 1, View{200, 300, 1}},
+		Case{"landscape", 640, 480, 1, View{400, 300, 1}},
+		Case{"wide", 1920, 800, 1, View{MaxViewWidth, 300, 1}},
+		Case{"phone", 390, 844, 3, View{200, 432, 3}},
+		Case{"tall", 100, 1000, 1, View{200, MaxViewHeight, 1}},
+		Case{"fractional scale", 400, 600, 1.5, View{200, 300, 1}},
+		Case{"no window", 0, 0, 2, View{200, 300, 1}},
+	}
+	for _, cs := range cases {
+		if v := NewView(cs.w, cs.h, cs.scale); v != cs.view {
+			t.Error(cs.t, v, cs.view)
+		}
+	}
+}
+
+func TestBoardPlace(t *testing.T) {
+	defer func(v View) { Viewport = v }(Viewport)
+	defer ApplySettings(Setting)
+	type Case struct {
+		t      string
+		view   View
+		left   bool
+		origin Point
+	}
+	cases := []Case{
+		Case{"design", View{200, 300, 1}, false, Point{10, 34}},
+		Case{"design left hand", View{200, 300, 1}, true, Point{62, 34}},
+		Case{"landscape", View{400, 300, 1}, false, Point{76, 34}},
+		Case{"landscape left hand", View{400, 300, 1}, true, Point{196, 34}},
+		Case{"narrow landscape", View{300, 300, 1}, false, Point{60, 34}},
+		Case{"phone", View{200, 432, 3}, false, Point{10, 166}},
+	}
+	for _, cs := range cases {
+		Viewport = cs.view
+		s := Setting
+		s.LeftHanded = cs.left
+		ApplySettings(s)
+		b := NewBoard()
+		b.Place()
+		if (Point{b.OriginX, b.OriginY}) != cs.origin {
+			t.Error(cs.t, b.OriginX, b.OriginY)
+		}
+		if !cs.view.Landscape() {
+			// the rotated numbers are inside the view, off the board
+			x := b.HUDX()
+			if x-NumberWidth-NumberHeight < 0 || x-NumberWidth > cs.view.Width {
+				t.Error(cs.t, "hud outside", x)
+			}
+			if x-NumberWidth-NumberHeight < b.OriginX+BoardWidth*StoneWidth && b.OriginX < x-NumberWidth {
+				t.Error(cs.t, "hud on the board", x)
+			}
+			continue
+		}
+		// the panel is beside the board on the free side, inside the view
+		x := b.PanelX()
+		if x < 0 || x+PanelWidth > cs.view.Width {
+			t.Error(cs.t, "panel outside", x)
+		}
+		if x < b.OriginX+BoardWidth*StoneWidth && b.OriginX < x+PanelWidth {
+			t.Error(cs.t, "panel on the board", x)
+		}
+		if b.Beside(x+1) != true {
+			t.Error(cs.t, "panel not beside")
+		}
+	}
+}
+
+func TestMenuItemAtView(t *testing.T) {
+	defer func(v View) { Viewport = v }(Viewport)
+	m := MainMenu()
+	Viewport = View{400, 400, 1}
+	if i := m.ItemAt(Viewport.Left()+MenuX, Viewport.Top()+MenuY+MenuItemHeight); i != 1 {
+		t.Error("offset", i)
+	}
+	if i := m.ItemAt(MenuX, MenuY); i != -1 {
+		t.Error("outside", i)
+	}
+}
+
+func TestEditorView(t *testing.T) {
+	defer func(v View) { Viewport = v }(Viewport)
+	Viewport = View{400, 400, 1}
+	e := NewEditor()
+	if e.Board.OriginX != Viewport.Left()+10 || e.Board.OriginY != Viewport.Top()+ScreenHeight-10-StoneHeight*BoardHeight {
+		t.Error("board", e.Board.OriginX, e.Board.OriginY)
+	}
+	g := NewGame()
+	e.Press(g, Viewport.Left()+BrushX, Viewport.Top()+BrushY+2*StoneHeight)
+	if e.Brush != Brushes[2] {
+		t.Error("brush", e.Brush)
+	}
+	e.Press(g, Viewport.Left()+EditPickX, Viewport.Top()+EditPickY)
+	if len(e.Pick) != 1 {
+		t.Error("pick", e.Pick)
+	}
+	e.Press(g, BrushX, BrushY)
+	if e.Brush != Brushes[2] {
+		t.Error("outside", e.Brush)
+	}
+}
@@ -0,0 +1,17 @@
+//go:build js
+// +build js
+
+package main
+
+import (
+	"syscall/js"
+)
+
+// SystemLocale is the language of the browser.
+func SystemLocale() string {
+	nav := js.Global().Get("navigator")
+	if nav.IsUndefined() || nav.Get("language").IsUndefined() {
+		return ""
+	}
+	return nav.Get("language").String()
+}
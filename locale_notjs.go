@@ -0,0 +1,18 @@
+//go:build !js
+// +build !js
+
+package main
+
+import (
+	"os"
+)
+
+// SystemLocale is the locale of the environment, as POSIX looks it up.
+func SystemLocale() string {
+	for _, key := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := os.Getenv(key); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -3,34 +3,32 @@ package main
 import (
 	"embed"
 	"fmt"
-	"image"
 	"image/color"
-	"image/png"
-	"io"
 	"log"
 	"math"
 	"math/rand"
+	"strconv"
+	"time"
 
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/hajimehoshi/ebiten/v2/audio"
-	"github.com/hajimehoshi/ebiten/v2/audio/vorbis"
-	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
 )
 
-const Volume = 0.4
-
 type Stone struct {
 	Color  Color
 	Erased bool
 }
 
 func (s *Stone) Colored() bool {
-	return s.Color == Red || s.Color == Blue || s.Color == Green || s.Color == Yellow || s.Color == Pink || s.Color == Orange
+	return s.Color.Colored()
 }
 
 type Color int
 
+func (c Color) Colored() bool {
+	return c == Red || c == Blue || c == Green || c == Yellow || c == Pink || c == Orange
+}
+
 const (
 	None Color = iota
 	Red
@@ -61,23 +59,24 @@ var Colors []Color = []Color{
 	Jammer,
 }
 
-type Sound int
-
-const (
-	S1 Sound = iota
-	S2
-	S3
-	S4
-)
-
-var SoundNameMap map[string]Sound = map[string]Sound{
-	"asset/S1.ogg": S1,
-	"asset/S2.ogg": S2,
-	"asset/S3.ogg": S3,
-	"asset/S4.ogg": S4,
+var ColorNames map[Color]string = map[Color]string{
+	None:   "none",
+	Red:    "red",
+	Blue:   "blue",
+	Green:  "green",
+	Yellow: "yellow",
+	Pink:   "pink",
+	Orange: "orange",
+	Wall:   "wall",
+	Jammer: "jammer",
 }
 
-var SoundMap map[Sound]*audio.Player = map[Sound]*audio.Player{}
+func (c Color) String() string {
+	if name, ok := ColorNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("Color(%d)", int(c))
+}
 
 type Step int
 
@@ -88,12 +87,21 @@ const (
 	WaitErase
 	CauseJammer
 	GameOver
+	Select
+	Clear
+	Edit
+	Loading
 )
 
 const (
+	TPS = 30
+
 	ScreenWidth  = 200
 	ScreenHeight = 300
 
+	// top of the right column is kept for numbers of the mode
+	HUDTop = 3 * NumberWidth
+
 	BoardWidth  = 8
 	BoardHeight = 16
 
@@ -111,7 +119,12 @@ const (
 	AlphaWidth  = 16
 	AlphaHeight = 16
 
+	// cells of asset/font.png
+	FontWidth  = 8
+	FontHeight = 16
+
 	WaitEraseFrame = 15
+	ToastFrame     = 3 * TPS
 
 	Cross  = 10
 	Equal  = 11
@@ -119,12 +132,10 @@ const (
 	NumE   = 13
 	NumN   = 14
 	NumD   = 15
+	Plus   = 16
 )
 
 var Texture *ebiten.Image
-var AudioCtx *audio.Context
-var Music *audio.Player
-var MusicOff *audio.Player
 var StoneImages map[Color]*ebiten.Image
 var NumberImages map[int]*ebiten.Image
 var AlphaImages map[rune]*ebiten.Image
@@ -132,49 +143,13 @@ var AlphaImages map[rune]*ebiten.Image
 //go:embed asset/*
 var asset embed.FS
 
-func PlayMusic(on bool) {
-	if on {
-		t := MusicOff.Current()
-		Music.Seek(t)
-		Music.Play()
-		MusicOff.Pause()
-	} else {
-		t := Music.Current()
-		MusicOff.Seek(t)
-		MusicOff.Play()
-		Music.Pause()
-	}
-}
-
-func PlaySound(s Sound) {
-	if s, ok := SoundMap[s]; ok {
-		s.SetVolume(Volume)
-		s.Rewind()
-		s.Play()
-	}
-}
-
 func (g *Game) Next() *Stone {
-	if len(g.Buffer) == 0 {
-		level := 3
-		if g.Turn > 24 {
-			level++
-		}
-		if g.Turn > 48 {
-			level++
-		}
-		if g.Turn > 72 {
-			level++
-		}
-		colors := []Color{Red, Blue, Green, Yellow, Pink, Orange}[:level]
-		rand.Shuffle(len(colors), func(i, j int) {
-			colors[i], colors[j] = colors[j], colors[i]
-		})
-		g.Buffer = colors
+	s := g.Mode.Next(g)
+	if s != nil {
+		g.Dealt |= 1 << uint(s.Color)
+		g.Drawn++
 	}
-	var c Color
-	c, g.Buffer = g.Buffer[0], g.Buffer[1:]
-	return &Stone{Color: c}
+	return s
 }
 
 type Game struct {
@@ -183,10 +158,24 @@ type Game struct {
 	Pick                  []*Stone
 	PickX, PickY, PickLen int
 	Step                  Step
-	Wait                  int
-	PrevTouchID           int
-	MouseEnabled          bool
-	DebugString           string
+	Mode                  Mode
+	Menu                  *Menu
+	Editor                *Editor
+	Loader                *Loader
+	// stones moved in this FallStone step
+	Falling      bool
+	Wait         int
+	PrevTouchID  int
+	MouseEnabled bool
+	DebugString  string
+
+	// called instead of going back to the title when a game ends
+	Back   func(g *Game)
+	Events Bus
+	// stones are drawn from Rand and jammers from JammerRand, which a mode may seed in Start;
+	// apart, the stones do not change with how many jammers fell and the other way round
+	Rand       *rand.Rand
+	JammerRand *rand.Rand
 
 	FirstTouchID        ebiten.TouchID
 	FirstTouchPoint     Point
@@ -195,43 +184,111 @@ type Game struct {
 
 	SequentErase  int
 	EraseNum      int
+	Match         MatchResult
 	Turn          int
+	Frames        int
+	Stats         Stats
+	Rank          int
+	ToppedOut     bool
 	Score         int
 	ScoreEquation string
+	Scorer        Scorer
 	HighScore     int
 	Ticks         int
+
+	Unlocked map[string]bool
+	// colors dealt as a bit set, for achievements
+	Dealt uint
+	// stones dealt so far
+	Drawn int
+	// shown at the bottom while ToastWait counts down
+	Toast     string
+	ToastWait int
 }
 
 func NewGame() *Game {
 	g := &Game{
 		Board:        &Board{},
 		MouseEnabled: false,
+		Mode:         Endless{},
+	}
+	if records := LoadRecords(Endless{}); len(records) > 0 {
+		g.HighScore = records[0].Score
 	}
+	g.Events.Subscribe(ScoreHandler, StatsHandler, SoundHandler, RecordHandler, AchievementHandler)
+	g.Unlocked = LoadUnlocked()
+	Cosmetic = LoadCosmetics()
+	ApplySettings(LoadSettings())
 	g.Initialize()
 	return g
 }
 
+// Initialize goes back to the title, its board empty as in Endless.
 func (g *Game) Initialize() {
+	g.Mode = Endless{}
+	g.Reset()
+	g.Step = Title
+}
+
+// Start begins a new game of the mode.
+func (g *Game) Start(m Mode) {
+	g.Mode = m
+	g.Back = nil
+	g.Reset()
+	g.Step = Move
+}
+
+func (g *Game) Reset() {
 	g.Board.Initialize()
 	g.Buffer = nil
-	g.Step = Title
+	g.Scorer = DefaultScorer
 	g.Wait = 0
 	g.SequentErase = 0
 	g.EraseNum = 0
+	g.Match = MatchResult{}
 	g.Turn = 0
+	g.Frames = 0
+	g.Stats = NewStats()
+	g.Dealt = 0
+	g.Drawn = 0
+	g.Rank = -1
+	g.ToppedOut = false
 	g.Score = 0
+	g.Rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	g.JammerRand = rand.New(rand.NewSource(time.Now().UnixNano() + 1))
+	g.Mode.Start(g)
 	g.InitPick()
 }
 
+// End finishes the game at step.
+func (g *Game) End(step Step) {
+	g.Step = step
+	g.ToppedOut = step == GameOver && g.IsFull()
+	g.Emit(GameEnded{step})
+}
+
+// PickBottom returns the row the bottom of the pick goes to in column cx,
+// below 0 when not even one stone fits.
+func (g *Game) PickBottom(cx int) int {
+	height := g.Board.HeightAt(cx)
+	return PickMax - 1 + minInt(height-PickMax-1, 0)
+}
+
+// IsFull reports whether no column accepts a stone, the board is topped out.
 func (g *Game) IsFull() bool {
 	for x := 1; x < BoardWidth-1; x++ {
-		if g.Board.HeightAt(x) > 1 {
+		if g.PickBottom(x) >= 0 {
 			return false
 		}
 	}
 	return true
 }
 
+// HasLegalMove reports whether at least one stone of the pick can be cut into some column.
+func (g *Game) HasLegalMove() bool {
+	return len(g.Pick) > 0 && !g.IsFull()
+}
+
 func (g *Game) HeightAverage() float64 {
 	sum := 0.0
 	for x := 1; x < BoardWidth-1; x++ {
@@ -244,13 +301,13 @@ func (g *Game) UpdateTouch() {
 	for _, tid := range ebiten.TouchIDs() {
 		if g.FirstTouchID == 0 {
 			g.FirstTouchID = tid
-			x, y := ebiten.TouchPosition(tid)
+			x, y := TouchPosition(tid)
 			g.FirstTouchPoint = Point{x, y}
 			cx, cy := g.Board.PosToCell(x, y)
 			g.FirstTouchCursored = cx == g.PickX && cy == (g.PickY-g.PickLen)+1
 		}
 		if tid == g.FirstTouchID {
-			x, y := ebiten.TouchPosition(tid)
+			x, y := TouchPosition(tid)
 			cx, cy := g.Board.PosToCell(x, y)
 			g.AdjustPick(cx, cy)
 			g.FirstTouchLastPoint = Point{x, y}
@@ -268,10 +325,36 @@ func (g *Game) UpdateTouch() {
 	}
 }
 
+// UpdateKeys moves the pick by the keys bound in the settings, arrows and enter or space by default.
+func (g *Game) UpdateKeys() {
+	cy := g.PickY - g.PickLen + 1
+	switch {
+	case ActionJustPressed(ActLeft):
+		x := maxInt(g.PickX-1, 1)
+		g.AdjustPick(x, g.PickBottom(x)-g.PickLen+1)
+	case ActionJustPressed(ActRight):
+		x := minInt(g.PickX+1, BoardWidth-2)
+		g.AdjustPick(x, g.PickBottom(x)-g.PickLen+1)
+	case ActionJustPressed(ActMore):
+		g.AdjustPick(g.PickX, cy-1)
+	case ActionJustPressed(ActLess):
+		g.AdjustPick(g.PickX, cy+1)
+	case ActionJustPressed(ActCut):
+		g.FixPick()
+	default:
+		return
+	}
+	g.MouseEnabled = false
+}
+
 func (g *Game) ReservePick() {
 	n := ReserveNum - len(g.Pick)
 	for n > 0 {
-		g.Pick = append(g.Pick, g.Next())
+		s := g.Next()
+		if s == nil {
+			break
+		}
+		g.Pick = append(g.Pick, s)
 		n--
 	}
 }
@@ -329,28 +412,53 @@ func minInt(v, v2 int) int {
 
 func (g *Game) AdjustPick(cx, cy int) {
 	g.PickX = clampInt(cx, 1, BoardWidth-2)
-	height := g.Board.HeightAt(g.PickX)
-	g.PickY = PickMax - 1 + minInt(height-PickMax-1, 0)
-	g.PickLen = clampInt((g.PickY-maxInt(cy, 0))+1, 0, PickMax)
+	g.PickY = g.PickBottom(g.PickX)
+	g.PickLen = clampInt((g.PickY-maxInt(cy, 0))+1, 0, minInt(PickMax, len(g.Pick)))
 }
 
 func (g *Game) Update() error {
 	g.Ticks++
+	// follow the window and the handedness
+	g.Board.Place()
+	if g.ToastWait > 0 {
+		g.ToastWait--
+	}
+	if g.Step == Loading {
+		return g.UpdateLoading()
+	}
+	g.UpdateMusic()
+	switch g.Step {
+	case Move, FallStone, WaitErase, CauseJammer:
+		g.Frames++
+	}
 	switch g.Step {
 	case Title:
 		if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
 			g.MouseEnabled = true
-			g.Step = Move
-			PlayMusic(true)
+			g.OpenMenu(MainMenu())
 		}
-		if len(ebiten.TouchIDs()) > 0 {
-			g.Step = Move
-			PlayMusic(true)
+		if len(ebiten.TouchIDs()) > 0 || KeyJustPressed(ConfirmKeys...) {
+			g.OpenMenu(MainMenu())
 		}
+	case Select:
+		g.Menu.Update(g)
+	case Edit:
+		g.Editor.Update(g)
 	case Move:
+		if step, ok := g.Mode.Expired(g); ok {
+			g.End(step)
+			break
+		}
+		if !g.HasLegalMove() {
+			g.End(GameOver)
+			break
+		}
 		// move by mouse cursor
+		if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+			g.MouseEnabled = true
+		}
 		if g.MouseEnabled {
-			x, y := ebiten.CursorPosition()
+			x, y := CursorPosition()
 			cx, cy := g.Board.PosToCell(x, y)
 			g.AdjustPick(cx, cy)
 			if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
@@ -359,6 +467,8 @@ func (g *Game) Update() error {
 		}
 		// move by touch
 		g.UpdateTouch()
+		// move by keys
+		g.UpdateKeys()
 
 		/*
 			if inpututil.IsKeyJustPressed(ebiten.KeyH) {
@@ -397,24 +507,20 @@ func (g *Game) Update() error {
 			}
 		}
 	case FallStone:
-		if !g.Board.FallStone() {
-			if num := g.Board.MarkErase(); num > 0 {
+		if g.Board.FallStone() {
+			g.Falling = true
+		} else {
+			if g.Falling {
+				g.Falling = false
+				g.Emit(StonesLanded{})
+			}
+			g.Match = g.Board.MarkChanged()
+			if num := g.Match.Num(); num > 0 {
 				g.Wait = WaitEraseFrame
 				g.SequentErase++
 				g.EraseNum = num
-				score, scoreEquation := CalcScore(g.SequentErase, num)
-				g.Score += score
-				g.HighScore = maxInt(g.HighScore, g.Score)
-				g.ScoreEquation = scoreEquation
-				if g.SequentErase%4 == 1 {
-					PlaySound(S1)
-				} else if g.SequentErase%4 == 2 {
-					PlaySound(S2)
-				} else if g.SequentErase%4 == 3 {
-					PlaySound(S3)
-				} else if g.SequentErase%4 == 0 {
-					PlaySound(S4)
-				}
+				g.Emit(StonesMatched{g.Match, g.SequentErase, g.Board.Remaining() == 0})
+				g.Emit(ChainAdvanced{g.SequentErase})
 			} else {
 				g.Wait = 1
 			}
@@ -422,23 +528,21 @@ func (g *Game) Update() error {
 		}
 	case CauseJammer:
 		g.Turn++
-		if g.Turn%JammerTurn == 0 {
-			g.CauseJammer()
-		}
-		g.ReservePick()
-		if g.IsFull() {
-			g.Step = GameOver
-			PlayMusic(false)
-		} else {
+		step := g.Mode.TurnEnd(g)
+		g.Emit(TurnEnded{g.Turn, step})
+		if step == Move {
 			g.Step = Move
 			g.SequentErase = 0
+		} else {
+			g.End(step)
 		}
-	case GameOver:
-		if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
-			g.Initialize()
-		}
-		if len(ebiten.TouchIDs()) > 0 {
-			g.Initialize()
+	case GameOver, Clear:
+		if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) || len(ebiten.TouchIDs()) > 0 || KeyJustPressed(ConfirmKeys...) {
+			if g.Back != nil {
+				g.Back(g)
+			} else {
+				g.Initialize()
+			}
 		}
 	}
 	return nil
@@ -449,18 +553,23 @@ func (g *Game) CauseJammer() {
 	if g.Turn > 50 {
 		num++
 	}
+	var points []Point
 	for i := 0; i < num; i++ {
-		x := rand.Intn(BoardWidth-2) + 1
+		x := g.JammerRand.Intn(BoardWidth-2) + 1
 		y := g.Board.HeightAt(x) - 1
 		if y > 1 {
 			if c, ok := g.Board.At(x, y); ok {
 				*c = NewJammer()
+				points = append(points, Point{x, y})
 			} else {
 			}
 		} else {
 			continue
 		}
 	}
+	if len(points) > 0 {
+		g.Emit(JammerDropped{points})
+	}
 }
 
 func (g *Game) IsPickCollide(px, py int) bool {
@@ -475,6 +584,9 @@ func (g *Game) IsPickCollide(px, py int) bool {
 }
 
 func (g *Game) FixPick() {
+	if m, ok := g.Mode.(Guided); ok && !m.AllowCut(g) {
+		return
+	}
 	if g.PickLen > 0 {
 		for i, p := range g.Pick[:g.PickLen] {
 			if a, ok := g.Board.At(g.PickX, g.PickY-i); ok {
@@ -487,6 +599,7 @@ func (g *Game) FixPick() {
 				log.Panic("fix failed", g.PickX, g.PickY-i)
 			}
 		}
+		g.Emit(CutPlaced{g.PickX, g.PickY, g.Pick[:g.PickLen]})
 		g.PickY -= g.PickLen
 		g.Pick = g.Pick[g.PickLen:]
 		g.PickLen = 1
@@ -495,6 +608,10 @@ func (g *Game) FixPick() {
 }
 
 func (g *Game) Draw(r *ebiten.Image) {
+	if g.Step == Loading {
+		g.Loader.Draw(r)
+		return
+	}
 	r.Fill(color.Gray{Y: 0x80})
 	/*
 		var input string
@@ -510,10 +627,18 @@ func (g *Game) Draw(r *ebiten.Image) {
 		}
 	*/
 	g.DebugString = ""
+	left, top := Viewport.Left(), Viewport.Top()
+	if g.Step == Edit {
+		g.Editor.Draw(r)
+		return
+	}
 	avg := g.HeightAverage()
 	noise := math.Max((8.0-avg)*0.2, 0.0)
+	if Setting.ReducedMotion {
+		noise = 0
+	}
 	g.Board.Render(r, noise, g.Wait)
-	if g.Step != Title {
+	if g.Step != Title && g.Step != Select {
 		for i, p := range g.Pick {
 			cx, cy := g.PickX, g.PickY-i
 			if cy >= 0 {
@@ -523,29 +648,63 @@ func (g *Game) Draw(r *ebiten.Image) {
 				}
 			}
 		}
-		if g.SequentErase > 0 {
+		if Viewport.Landscape() {
+			g.DrawPanel(r)
+		} else if g.SequentErase > 0 {
 			f := (float64(g.Wait) / WaitEraseFrame)
 			dx := f * f * f * NumberWidth
-			RenderNumber(r, g.SequentErase, BoardWidth*StoneWidth/2+NumberWidth+int(dx), 0, false)
-			RenderEquation(r, g.ScoreEquation, ScreenWidth, ScreenHeight-32, true)
+			if Setting.ReducedMotion {
+				dx = 0
+			}
+			RenderNumber(r, g.SequentErase, g.Board.CenterX()+NumberWidth+int(dx), top, false)
+			RenderEquation(r, g.ScoreEquation, g.Board.HUDX(), Viewport.Height-32, top+HUDTop, true)
 		} else {
-			RenderNumber(r, g.Score, ScreenWidth, ScreenHeight-32, true)
+			RenderNumber(r, g.Score, g.Board.HUDX(), Viewport.Height-32, true)
 		}
+		g.Mode.Draw(g, r)
+	}
+	if g.Step == GameOver && g.ToppedOut {
+		RenderToppedOut(r, g.Board, g.Ticks)
 	}
 	if g.Step == GameOver {
-		RenderEnd(r, BoardWidth*StoneWidth/2-NumberWidth, StoneHeight*3, g.Ticks)
+		RenderEnd(r, g.Board.CenterX()-NumberWidth, top+StoneHeight*3, g.Ticks)
+	}
+	if (g.Step == GameOver || g.Step == Clear) && g.Rank >= 0 {
+		DrawTextAligned(r, Tf("RANK %d", g.Rank+1), g.Board.CenterX(), top+StoneHeight*2, AlignCenter)
+	}
+	if g.Step == Clear {
+		DrawTextAligned(r, T("CLEAR!"), g.Board.CenterX(), top+StoneHeight*5, AlignCenter)
+	}
+	if _, ok := g.Mode.(*TutorialMode); !ok && (g.Step == GameOver || g.Step == Clear) {
+		RenderStats(r, g.Stats, g.Board.OriginX-2, top+StoneHeight*6)
+	}
+	if g.ToastWait > 0 {
+		DrawText(r, g.Toast, left+2, Viewport.Height-16)
+	}
+	if g.Step == Select {
+		g.Menu.Draw(r)
 	}
 	if g.Step == Title {
+		// the title is laid on the board, wherever it is
+		left := g.Board.OriginX - 10
 		// ebitenutil.DebugPrint(r, "\n  cut'n'align\n  LD44 game by @neguse\n 2019 end of heisei generation\n\n\n\n  click to start\n\n\n\n\n\n\n  Very thanks to \n    @hajimehoshi\n    and my brother.")
-		ebitenutil.DebugPrintAt(r, "Very thanks to\n@hajimehoshi\nand my brother.", 32, ScreenHeight-60)
-		RenderNumber(r, g.HighScore, ScreenWidth, ScreenHeight-32, true)
-		RenderAlpha(r, "cutn", StoneWidth*1.5, StoneHeight*3)
-		RenderAlpha(r, "align", StoneWidth*2.5, StoneHeight*4)
-		RenderAlpha(r, "click", StoneWidth*1.5, StoneHeight*6)
-		RenderAlpha(r, "to", StoneWidth*3.5, StoneHeight*7)
-		RenderAlpha(r, "cut", StoneWidth*2.5, StoneHeight*8)
+		DrawText(r, T("Very thanks to\n@hajimehoshi\nand my brother."), left+32, Viewport.Height-60)
+		if Viewport.Landscape() {
+			g.DrawPanel(r)
+		} else {
+			RenderNumber(r, g.HighScore, g.Board.HUDX(), Viewport.Height-32, true)
+		}
+		RenderAlpha(r, "cutn", left+StoneWidth*1.5, top+StoneHeight*3)
+		RenderAlpha(r, "align", left+StoneWidth*2.5, top+StoneHeight*4)
+		if CurrentLang == English {
+			RenderAlpha(r, "click", left+StoneWidth*1.5, top+StoneHeight*6)
+			RenderAlpha(r, "to", left+StoneWidth*3.5, top+StoneHeight*7)
+			RenderAlpha(r, "cut", left+StoneWidth*2.5, top+StoneHeight*8)
+		} else {
+			DrawTextAligned(r, T("click to cut"), left+BoardWidth*StoneWidth/2, top+StoneHeight*7, AlignCenter)
+		}
 		// RenderAlpha(r, "@@@@@@", StoneWidth*1.5, StoneHeight*12+1)
-		RenderAlpha(r, "neguse", StoneWidth*1.5, StoneHeight*13+1)
+		RenderAlpha(r, "neguse", left+StoneWidth*1.5, top+StoneHeight*13+1)
 	}
 
 }
@@ -553,6 +712,9 @@ func (g *Game) Draw(r *ebiten.Image) {
 type Board struct {
 	Cell             [BoardWidth][BoardHeight]*Stone
 	OriginX, OriginY int
+
+	// colors at the last state without any match, see MarkChanged
+	stable [BoardWidth][BoardHeight]Color
 }
 
 func (b *Board) Initialize() {
@@ -579,19 +741,19 @@ func (b *Board) Initialize() {
 			*c = NewWall()
 		}
 	}
-	b.OriginX = 10
-	b.OriginY = -10 + ScreenHeight - StoneHeight*BoardHeight
+	b.Place()
+	b.Settle()
 }
 
 type Point struct {
 	x, y int
 }
 
-func HorizontalLines() [][]Point {
+func HorizontalLines(w, h int) [][]Point {
 	var lines [][]Point
-	for cy := 0; cy < BoardHeight; cy++ {
+	for cy := 0; cy < h; cy++ {
 		var line []Point
-		for cx := 0; cx < BoardWidth; cx++ {
+		for cx := 0; cx < w; cx++ {
 			line = append(line, Point{cx, cy})
 		}
 		lines = append(lines, line)
@@ -599,11 +761,11 @@ func HorizontalLines() [][]Point {
 	return lines
 }
 
-func VerticalLines() [][]Point {
+func VerticalLines(w, h int) [][]Point {
 	var lines [][]Point
-	for cx := 0; cx < BoardWidth; cx++ {
+	for cx := 0; cx < w; cx++ {
 		var line []Point
-		for cy := 0; cy < BoardHeight; cy++ {
+		for cy := 0; cy < h; cy++ {
 			line = append(line, Point{cx, cy})
 		}
 		lines = append(lines, line)
@@ -611,12 +773,12 @@ func VerticalLines() [][]Point {
 	return lines
 }
 
-func RightDownLines() [][]Point {
+func RightDownLines(w, h int) [][]Point {
 	rightDownLine := func(x, y int) []Point {
 		var line []Point
 		for i := 0; ; i++ {
 			cx, cy := x+i, y+i
-			if cx >= BoardWidth || cy >= BoardHeight {
+			if cx >= w || cy >= h {
 				break
 			}
 			line = append(line, Point{cx, cy})
@@ -624,21 +786,21 @@ func RightDownLines() [][]Point {
 		return line
 	}
 	var lines [][]Point
-	for y := 0; y < BoardHeight; y++ {
+	for y := 0; y < h; y++ {
 		lines = append(lines, rightDownLine(0, y))
 	}
-	for x := 1; x < BoardWidth; x++ {
+	for x := 1; x < w; x++ {
 		lines = append(lines, rightDownLine(x, 0))
 	}
 	return lines
 }
 
-func RightUpLines() [][]Point {
+func RightUpLines(w, h int) [][]Point {
 	rightUpLine := func(x, y int) []Point {
 		var line []Point
 		for i := 0; ; i++ {
 			cx, cy := x+i, y-i
-			if cx >= BoardWidth || cy < 0 {
+			if cx >= w || cy < 0 {
 				break
 			}
 			line = append(line, Point{cx, cy})
@@ -646,11 +808,11 @@ func RightUpLines() [][]Point {
 		return line
 	}
 	var lines [][]Point
-	for y := 0; y < BoardHeight; y++ {
+	for y := 0; y < h; y++ {
 		lines = append(lines, rightUpLine(0, y))
 	}
-	for x := 1; x < BoardWidth; x++ {
-		lines = append(lines, rightUpLine(x, BoardHeight-1))
+	for x := 1; x < w; x++ {
+		lines = append(lines, rightUpLine(x, h-1))
 	}
 	return lines
 }
@@ -678,38 +840,27 @@ func (b *Board) MarkEraseAt(cx, cy int) bool {
 	return false
 }
 
-func (b *Board) MarkErase() int {
+// Count counts stones of the color left after erasing.
+func (b *Board) Count(color Color) int {
 	num := 0
-	var lines [][]Point
-
-	lines = append(lines, HorizontalLines()...)
-	lines = append(lines, VerticalLines()...)
-	lines = append(lines, RightDownLines()...)
-	lines = append(lines, RightUpLines()...)
-
-	for _, line := range lines {
-		sequent := 0
-		for i := 1; i <= len(line); i++ {
-			p := line[sequent]
-			if i < len(line) {
-				p2 := line[i]
-				if c, ok := b.At(p.x, p.y); ok && *c != nil {
-					if c2, ok := b.At(p2.x, p2.y); ok && *c2 != nil {
-						if (*c).Color == (*c2).Color && (*c).Colored() {
-							continue
-						}
-					}
-				}
+	for cy := 0; cy < BoardHeight; cy++ {
+		for cx := 0; cx < BoardWidth; cx++ {
+			if c, ok := b.At(cx, cy); ok && *c != nil && (*c).Color == color && !(*c).Erased {
+				num++
 			}
-			n := i - sequent
-			if n >= 3 {
-				for _, cp := range line[sequent:i] {
-					if b.MarkEraseAt(cp.x, cp.y) {
-						num++
-					}
-				}
+		}
+	}
+	return num
+}
+
+// Remaining counts stones left after erasing, walls excluded.
+func (b *Board) Remaining() int {
+	num := 0
+	for cy := 0; cy < BoardHeight; cy++ {
+		for cx := 0; cx < BoardWidth; cx++ {
+			if c, ok := b.At(cx, cy); ok && *c != nil && (*c).Color != Wall && !(*c).Erased {
+				num++
 			}
-			sequent = i
 		}
 	}
 	return num
@@ -734,7 +885,8 @@ func (b *Board) FallStone() bool {
 		for cy := BoardHeight - 1; cy >= 0; cy-- {
 			if c, ok := b.At(cx, cy); ok {
 				if c2, ok := b.At(cx, cy-1); ok {
-					if (*c) == nil && (*c2) != nil {
+					// walls stay where they are
+					if (*c) == nil && (*c2) != nil && (*c2).Color != Wall {
 						*c, *c2 = *c2, *c
 						falled = true
 					}
@@ -758,12 +910,14 @@ func (b *Board) RenderStone(r *ebiten.Image, cx, cy int, s *Stone, noise float64
 	if s == nil {
 		log.Panic("s must not nil")
 	}
-	opt := &ebiten.DrawImageOptions{Filter: ebiten.FilterNearest}
+	opt := SpriteOptions()
 	// sugoi nazo no erasing animation
 	if s.Erased {
 		opt.GeoM.Translate(-float64(StoneWidth*0.5)+3.0, -float64(StoneHeight)*0.5)
 		r := float64(wait)
-		opt.GeoM.Rotate(r)
+		if !Setting.ReducedMotion {
+			opt.GeoM.Rotate(r)
+		}
 		s := float64(wait) / float64(WaitEraseFrame)
 		opt.GeoM.Scale(s*s*s, s*s*s)
 		opt.GeoM.Translate(float64(StoneWidth*0.5), float64(StoneHeight)*0.5)
@@ -771,9 +925,42 @@ func (b *Board) RenderStone(r *ebiten.Image, cx, cy int, s *Stone, noise float64
 	opt.GeoM.Translate(float64(b.OriginX)+(rand.Float64()-0.5)*noise, float64(b.OriginY)+(rand.Float64()-0.5)*noise)
 	opt.GeoM.Translate(float64(cx*StoneWidth), float64(cy*StoneHeight))
 
+	// skins would spoil the colors of the palettes
+	if s.Color.Colored() && FindPalette(Setting.Palette).Name == ClassicPalette {
+		ApplySkin(opt)
+	}
 	if image, ok := StoneImages[s.Color]; ok {
-		r.DrawImage(image, opt)
+		Blit(r, image, opt)
+	}
+}
+
+// Place puts the board at the bottom of the view,
+// keeping the free column beside it under the thumb.
+func (b *Board) Place() {
+	b.OriginX = Viewport.Left() + 10
+	if Setting.LeftHanded {
+		b.OriginX = Viewport.Left() + ScreenWidth - BoardWidth*StoneWidth - 10
+	}
+	if Viewport.Landscape() {
+		// the board and the panel side by side in the middle
+		b.OriginX = (Viewport.Width - BoardWidth*StoneWidth - PanelGap - PanelWidth) / 2
+		if Setting.LeftHanded {
+			b.OriginX += PanelWidth + PanelGap
+		}
 	}
+	b.OriginY = Viewport.Height - 10 - StoneHeight*BoardHeight
+}
+
+func (b *Board) CenterX() int {
+	return b.OriginX + BoardWidth*StoneWidth/2
+}
+
+// Beside reports whether x is on the free side of the board, the wall included.
+func (b *Board) Beside(x int) bool {
+	if Setting.LeftHanded {
+		return x < b.OriginX+StoneWidth
+	}
+	return x >= b.OriginX+(BoardWidth-1)*StoneWidth
 }
 
 func (b *Board) PosToCell(x, y int) (cx, cy int) {
@@ -791,26 +978,26 @@ func (b *Board) HeightAt(x int) int {
 }
 
 func (b *Board) RenderCursor(r *ebiten.Image, cx, cy int) {
-	opt := &ebiten.DrawImageOptions{Filter: ebiten.FilterNearest}
+	opt := SpriteOptions()
 	opt.GeoM.Translate(float64(b.OriginX), float64(b.OriginY))
 	opt.GeoM.Translate(float64(cx*StoneWidth), float64(cy*StoneHeight))
 
 	if image, ok := StoneImages[Cursor]; ok {
-		r.DrawImage(image, opt)
+		Blit(r, image, opt)
 	}
 }
 
 func (b *Board) Render(r *ebiten.Image, noise float64, wait int) {
 	for cx := 0; cx < BoardWidth; cx++ {
 		for cy := 0; cy < BoardHeight; cy++ {
-			opt := &ebiten.DrawImageOptions{Filter: ebiten.FilterNearest}
+			opt := SpriteOptions()
 			opt.GeoM.Translate(float64(b.OriginX)+(rand.Float64()-0.5)*noise, float64(b.OriginY)+(rand.Float64()-0.5)*noise)
 			opt.GeoM.Translate(float64(cx*StoneWidth), float64(cy*StoneHeight))
 			// bg
 			if cy == 0 {
-				r.DrawImage(StoneImages[Limit], opt)
+				Blit(r, StoneImages[Limit], opt)
 			} else {
-				r.DrawImage(StoneImages[None], opt)
+				Blit(r, StoneImages[None], opt)
 			}
 
 			// Stone
@@ -821,181 +1008,126 @@ func (b *Board) Render(r *ebiten.Image, noise float64, wait int) {
 	}
 }
 
-// x, y is right bottom
-func RenderEquation(r *ebiten.Image, equation string, x, y int, rot bool) {
-	ctoi := func(ch rune) int {
-		switch ch {
-		case 'x':
-			return Cross
-		case '=':
-			return Equal
-		case '.':
-			return Period
-		default:
-			return int(ch) - int('0')
-		}
-	}
-	for i, c := range equation {
-		opt := &ebiten.DrawImageOptions{Filter: ebiten.FilterNearest}
-		if rot {
-			opt.GeoM.Rotate(math.Pi / 2)
+// NumberSprite is the index in NumberImages of a character of an equation.
+func NumberSprite(ch rune) int {
+	switch ch {
+	case 'x':
+		return Cross
+	case '=':
+		return Equal
+	case '.':
+		return Period
+	case '+':
+		return Plus
+	default:
+		return int(ch) - int('0')
+	}
+}
+
+// x, y is right bottom, lines are wrapped not to go above top
+func RenderEquation(r *ebiten.Image, equation string, x, y, top int, rot bool) {
+	// long equations continue on the next column to the left, last line at x,
+	// or to the right from the first line for the left hand, not to leave the view
+	lines := SplitEquation(equation, (y-top)/NumberWidth+1)
+	for l, line := range lines {
+		lx := x - (len(lines)-1-l)*NumberHeight
+		if Setting.LeftHanded {
+			lx = x + l*NumberHeight
+		}
+		for i, c := range line {
+			opt := SpriteOptions()
+			if rot {
+				opt.GeoM.Rotate(math.Pi / 2)
+			}
+			opt.GeoM.Translate(float64(lx-NumberWidth), float64(y+(-len(line)+i+1)*NumberWidth))
+			Blit(r, NumberImages[NumberSprite(c)], opt)
 		}
-		opt.GeoM.Translate(float64(x-NumberWidth), float64(y+(-len(equation)+i+1)*NumberWidth))
-		r.DrawImage(NumberImages[ctoi(c)], opt)
 	}
 }
 
 // x, y is right bottom
+// RenderAlpha draws the big letters of the atlas, other runes in the font.
 func RenderAlpha(r *ebiten.Image, str string, x, y int) {
 	for i, c := range str {
+		if image, ok := AlphaImages[c]; ok {
+			opt := SpriteOptions()
+			opt.GeoM.Translate(float64(x+AlphaWidth*i), float64(y))
+			Blit(r, image, opt)
+			continue
+		}
 		opt := &ebiten.DrawImageOptions{Filter: ebiten.FilterNearest}
 		opt.GeoM.Translate(float64(x+AlphaWidth*i), float64(y))
-		r.DrawImage(AlphaImages[c], opt)
+		g := DefaultFont.Glyph(c)
+		opt.GeoM.Translate(float64(AlphaWidth-g.Width)/2, 0)
+		opt.ColorM.Scale(0, 0, 0, 1)
+		Blit(r, g.Image, opt)
 	}
 }
 
 // perhaps x, y is right bottom
 func RenderNumber(r *ebiten.Image, n int, x, y int, rot bool) {
-	opt := &ebiten.DrawImageOptions{Filter: ebiten.FilterNearest}
+	opt := SpriteOptions()
 	if rot {
 		opt.GeoM.Rotate(math.Pi / 2)
 	}
 	opt.GeoM.Translate(float64(x-NumberWidth), float64(y))
-	r.DrawImage(NumberImages[n%10], opt)
+	Blit(r, NumberImages[n%10], opt)
 	if n >= 10 {
 		RenderNumber(r, n/10, x, y-NumberWidth, rot)
 	}
 }
 
+// x, y is right top
+func RenderNumberTop(r *ebiten.Image, n int, x, y int) {
+	RenderNumber(r, n, x, y+(len(strconv.Itoa(n))-1)*NumberWidth, true)
+}
+
 func RenderEnd(r *ebiten.Image, x, y int, ticks int) {
+	if Setting.ReducedMotion {
+		ticks = 0
+	}
 	for i, n := range []int{NumE, NumN, NumD} {
 		ny := (math.Cos((float64(ticks)+float64(i))*0.1) + 1.0) * float64(BoardHeight*StoneHeight) * 0.25
-		opt := &ebiten.DrawImageOptions{Filter: ebiten.FilterNearest}
+		opt := SpriteOptions()
 		opt.GeoM.Translate(float64(x+NumberWidth*i), float64(y)+ny)
-		r.DrawImage(NumberImages[n], opt)
+		Blit(r, NumberImages[n], opt)
+	}
+}
+
+// RenderToppedOut blinks the top stone of every column.
+func RenderToppedOut(r *ebiten.Image, b *Board, ticks int) {
+	if ticks/8%2 == 0 {
+		for x := 1; x < BoardWidth-1; x++ {
+			b.RenderCursor(r, x, b.HeightAt(x))
+		}
 	}
+	DrawTextAligned(r, T("TOPPED OUT"), b.CenterX(), b.OriginY-StoneHeight, AlignCenter)
 }
 
 func NewBoard() *Board {
 	return &Board{}
 }
 
-func init() {
-	StoneImages = make(map[Color]*ebiten.Image)
-	NumberImages = make(map[int]*ebiten.Image)
-	AlphaImages = make(map[rune]*ebiten.Image)
-	tf, err := asset.Open("asset/texture.png")
-	if err != nil {
-		log.Panic(err)
-	}
-	defer tf.Close()
-	var texture image.Image
-	if texture, err = png.Decode(tf); err != nil {
-		log.Panic(err)
-	}
-	Texture = ebiten.NewImageFromImage(texture)
-
-	stoneSubImage := func(i int) *ebiten.Image {
-		x := i % 8
-		y := i / 8
-		image := Texture.SubImage(
-			image.Rectangle{
-				image.Point{StoneWidth * x, StoneHeight * y},
-				image.Point{StoneWidth * (x + 1), StoneHeight * (y + 1)}})
-		return image.(*ebiten.Image)
-	}
-	for _, c := range Colors {
-		StoneImages[c] = stoneSubImage(int(c))
-	}
-	numberSubImage := func(i int) *ebiten.Image {
-		x := i % 8
-		y := i / 8
-		image := Texture.SubImage(
-			image.Rectangle{
-				image.Point{NumberWidth * x, 32 + NumberHeight*y},
-				image.Point{NumberWidth * (x + 1), 32 + NumberHeight*(y+1)}})
-		return image.(*ebiten.Image)
-	}
-	for i := 0; i < 16; i++ {
-		NumberImages[i] = numberSubImage(i)
-	}
-	alphaSubImage := func(i int) *ebiten.Image {
-		x := i % 8
-		y := i / 8
-		image := Texture.SubImage(
-			image.Rectangle{
-				image.Point{AlphaWidth * x, 96 + AlphaHeight*y},
-				image.Point{AlphaWidth * (x + 1), 96 + AlphaHeight*(y+1)}})
-		return image.(*ebiten.Image)
-	}
-	for i, c := range []rune{'c', 'u', 't', 'n', 'a', 'l', 'i', 'g', 'k', 'o', '@', 'e', 's'} {
-		AlphaImages[c] = alphaSubImage(i)
-	}
-
-	AudioCtx = audio.NewContext(44100)
-
-	{
-		mf, err := asset.Open("asset/bgm.ogg")
-		if err != nil {
-			log.Panic(err)
-		}
-		defer mf.Close()
-		s, err := vorbis.Decode(AudioCtx, mf.(io.ReadSeeker))
-		if err != nil {
-			log.Panic(err)
-		}
-		Music, err = audio.NewPlayer(AudioCtx, audio.NewInfiniteLoop(s, s.Length()))
-		if err != nil {
-			log.Panic(err)
-		}
-		Music.SetVolume(Volume)
-	}
-	{
-		mf, err := asset.Open("asset/bgm_off.ogg")
-		if err != nil {
-			log.Panic(err)
-		}
-		defer mf.Close()
-		s, err := vorbis.Decode(AudioCtx, mf.(io.ReadSeeker))
-		if err != nil {
-			log.Panic(err)
-		}
-		MusicOff, err = audio.NewPlayer(AudioCtx, audio.NewInfiniteLoop(s, s.Length()))
-		if err != nil {
-			log.Panic(err)
-		}
-		MusicOff.SetVolume(Volume)
-	}
-
-	for sname, s := range SoundNameMap {
-		sf, err := asset.Open(sname)
-		if err != nil {
-			log.Panic(err)
-		}
-		defer sf.Close()
-		v, err := vorbis.Decode(AudioCtx, sf.(io.ReadSeeker))
-		if err != nil {
-			log.Panic(err)
-		}
-		vdata, err := io.ReadAll(v)
-		if err != nil {
-			log.Panic(err)
+// Clone copies the board with its stones.
+func (b *Board) Clone() *Board {
+	nb := *b
+	for cx := 0; cx < BoardWidth; cx++ {
+		for cy := 0; cy < BoardHeight; cy++ {
+			if s := b.Cell[cx][cy]; s != nil {
+				ns := *s
+				nb.Cell[cx][cy] = &ns
+			}
 		}
-		p := audio.NewPlayerFromBytes(AudioCtx, vdata)
-		SoundMap[s] = p
 	}
-
-	PlayMusic(false)
-}
-
-func (g *Game) Layout(outsideWidth, outsideHeight int) (screenWidth, screenHeight int) {
-	return ScreenWidth, ScreenHeight
+	return &nb
 }
 
 func main() {
-	ebiten.SetMaxTPS(30)
+	ebiten.SetMaxTPS(TPS)
 	ebiten.SetWindowTitle("cut'n'align")
+	ebiten.SetWindowResizable(true)
 	g := NewGame()
+	g.Load(AssetSteps(asset))
 	if err := ebiten.RunGame(g); err != nil {
 		log.Fatal(err)
 	}
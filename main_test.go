@@ -1,6 +1,8 @@
 package main
 
 import (
+	"math/rand"
+	"reflect"
 	"testing"
 )
 
@@ -130,6 +132,27 @@ func TestGameAdjustPick(t *testing.T) {
 				P{"-1", 1, -1, 1, -1, 0},
 			},
 		},
+		Case{
+			"other column full",
+			[]C{
+				C{1, 1, Red},
+			},
+			[]P{
+				P{"free", 2, PickMax - 1, 2, PickMax - 1, 1},
+				P{"clamped", 0, 0, 1, -1, 0},
+			},
+		},
+		Case{
+			"one stone left",
+			[]C{
+				C{6, 2, Wall},
+			},
+			[]P{
+				P{" 0", 6, 0, 6, 0, 1},
+				P{"-1", 6, -1, 6, 0, 1},
+				P{"clamped", 7, -1, 6, 0, 1},
+			},
+		},
 	}
 	for _, cs := range cases {
 		g := NewGame()
@@ -264,3 +287,190 @@ func TestBoardMarkErase(t *testing.T) {
 		}
 	}
 }
+
+// markEraseReference is MarkErase as it was before lines were precomputed.
+func markEraseReference(b *Board) int {
+	num := 0
+	var lines [][]Point
+
+	lines = append(lines, HorizontalLines(BoardWidth, BoardHeight)...)
+	lines = append(lines, VerticalLines(BoardWidth, BoardHeight)...)
+	lines = append(lines, RightDownLines(BoardWidth, BoardHeight)...)
+	lines = append(lines, RightUpLines(BoardWidth, BoardHeight)...)
+
+	for _, line := range lines {
+		sequent := 0
+		for i := 1; i <= len(line); i++ {
+			p := line[sequent]
+			if i < len(line) {
+				p2 := line[i]
+				if c, ok := b.At(p.x, p.y); ok && *c != nil {
+					if c2, ok := b.At(p2.x, p2.y); ok && *c2 != nil {
+						if (*c).Color == (*c2).Color && (*c).Colored() {
+							continue
+						}
+					}
+				}
+			}
+			n := i - sequent
+			if n >= 3 {
+				for _, cp := range line[sequent:i] {
+					if b.MarkEraseAt(cp.x, cp.y) {
+						num++
+					}
+				}
+			}
+			sequent = i
+		}
+	}
+	return num
+}
+
+func randomDrop(b *Board, rnd *rand.Rand, n int) {
+	colors := []Color{Red, Blue, Green, Yellow, Jammer}
+	for i := 0; i < n; i++ {
+		x := rnd.Intn(BoardWidth-2) + 1
+		y := b.HeightAt(x) - 1
+		if c, ok := b.At(x, y); ok && y > 0 {
+			*c = &Stone{Color: colors[rnd.Intn(len(colors))]}
+		}
+	}
+}
+
+func settle(b *Board) {
+	for {
+		for b.FallStone() {
+		}
+		if b.MarkErase().Num() == 0 {
+			return
+		}
+		b.Erase()
+	}
+}
+
+func erasedEqual(b, b2 *Board) bool {
+	for cx := 0; cx < BoardWidth; cx++ {
+		for cy := 0; cy < BoardHeight; cy++ {
+			s, s2 := b.Cell[cx][cy], b2.Cell[cx][cy]
+			if (s == nil) != (s2 == nil) || (s != nil && s.Erased != s2.Erased) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func TestBoardMarkEraseEquivalence(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		b := NewBoard()
+		b.Initialize()
+		randomDrop(b, rnd, rnd.Intn(60))
+		settle(b)
+		randomDrop(b, rnd, rnd.Intn(6)+1)
+
+		ref, full, changed := b.Clone(), b.Clone(), b.Clone()
+		num := markEraseReference(ref)
+		rf := full.MarkErase()
+		rc := changed.MarkChanged()
+		if rf.Num() != num || rc.Num() != num {
+			t.Error(i, "num", num, rf.Num(), rc.Num())
+		}
+		if !reflect.DeepEqual(rf, rc) {
+			t.Error(i, "result", rf, rc)
+		}
+		if !erasedEqual(ref, full) || !erasedEqual(ref, changed) {
+			t.Error(i, "erased mismatch")
+		}
+	}
+}
+
+func benchmarkBoards() []*Board {
+	rnd := rand.New(rand.NewSource(1))
+	var boards []*Board
+	for i := 0; i < 64; i++ {
+		b := NewBoard()
+		b.Initialize()
+		randomDrop(b, rnd, 50)
+		settle(b)
+		randomDrop(b, rnd, 3)
+		boards = append(boards, b)
+	}
+	return boards
+}
+
+func BenchmarkBoardMarkEraseReference(b *testing.B) {
+	boards := benchmarkBoards()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// marking changes the board, every pass starts from a fresh copy
+		b.StopTimer()
+		bd := boards[i%len(boards)].Clone()
+		b.StartTimer()
+		markEraseReference(bd)
+	}
+}
+
+func BenchmarkBoardMarkErase(b *testing.B) {
+	boards := benchmarkBoards()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		bd := boards[i%len(boards)].Clone()
+		b.StartTimer()
+		bd.markLines(func(int) bool { return true })
+	}
+}
+
+func BenchmarkBoardMarkChanged(b *testing.B) {
+	boards := benchmarkBoards()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		bd := boards[i%len(boards)].Clone()
+		b.StartTimer()
+		bd.MarkChanged()
+	}
+}
+
+func TestGameHasLegalMove(t *testing.T) {
+	type Case struct {
+		t       string
+		heights []int
+		pick    int
+		legal   bool
+		full    bool
+	}
+	// heights are the top rows of columns from x = 1, 0 leaves the column empty
+	cases := []Case{
+		Case{"empty", []int{}, PickMax, true, false},
+		Case{"some columns blocked", []int{1, 1, 1}, PickMax, true, false},
+		Case{"one stone fits", []int{1, 1, 1, 1, 1, 2}, PickMax, true, false},
+		Case{"all blocked", []int{1, 1, 1, 1, 1, 1}, PickMax, false, true},
+		Case{"last column empty", []int{1, 1, 1, 1, 1, 0}, PickMax, true, false},
+		Case{"no pick", []int{}, 0, false, false},
+	}
+	for _, cs := range cases {
+		g := NewGame()
+		for i, top := range cs.heights {
+			for y := top; top > 0 && y < BoardHeight-1; y++ {
+				if c, ok := g.Board.At(i+1, y); ok {
+					*c = &Stone{Color: Wall}
+				}
+			}
+		}
+		g.Pick = g.Pick[:cs.pick]
+		if l := g.HasLegalMove(); l != cs.legal {
+			t.Error(cs.t, "legal", l, cs.legal)
+		}
+		if f := g.IsFull(); f != cs.full {
+			t.Error(cs.t, "full", f, cs.full)
+		}
+		if cs.full {
+			g.End(g.Mode.TurnEnd(g))
+			if g.Step != GameOver || !g.ToppedOut {
+				t.Error(cs.t, "not topped out", g.Step, g.ToppedOut)
+			}
+		}
+	}
+}
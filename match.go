@@ -0,0 +1,201 @@
+package main
+
+type Direction int
+
+const (
+	Horizontal Direction = iota
+	Vertical
+	RightDown
+	RightUp
+)
+
+func (d Direction) Diagonal() bool {
+	return d == RightDown || d == RightUp
+}
+
+// MatchGroup is one run of three or more same colored stones.
+type MatchGroup struct {
+	Cells     []Point
+	Color     Color
+	Direction Direction
+}
+
+func (m MatchGroup) Len() int {
+	return len(m.Cells)
+}
+
+// MatchResult is what MarkErase found in one step.
+// Jammers are the jammers erased next to the groups.
+type MatchResult struct {
+	Groups  []MatchGroup
+	Jammers []Point
+}
+
+// Num counts stones the way MarkErase used to,
+// a stone in two groups is counted twice.
+func (r MatchResult) Num() int {
+	num := 0
+	for _, m := range r.Groups {
+		num += m.Len()
+	}
+	return num
+}
+
+// Stones counts erased stones, each once.
+func (r MatchResult) Stones() int {
+	seen := map[Point]bool{}
+	for _, m := range r.Groups {
+		for _, p := range m.Cells {
+			seen[p] = true
+		}
+	}
+	return len(seen)
+}
+
+// Directions counts the directions matched at once.
+func (r MatchResult) Directions() int {
+	seen := map[Direction]bool{}
+	for _, m := range r.Groups {
+		seen[m.Direction] = true
+	}
+	return len(seen)
+}
+
+func (r MatchResult) Erasure() Erasure {
+	e := Erasure{
+		Stones:  r.Num(),
+		Groups:  len(r.Groups),
+		Jammers: len(r.Jammers),
+	}
+	for _, m := range r.Groups {
+		if m.Direction.Diagonal() {
+			e.Diagonals++
+		}
+	}
+	return e
+}
+
+// Line is one row, column or diagonal that MarkErase scans.
+type Line struct {
+	Points    []Point
+	Direction Direction
+}
+
+// Lines are built once per board size, together with the lines
+// passing through each cell so changed cells can be checked alone.
+type Lines struct {
+	Width, Height int
+	Lines         []Line
+	cellLines     [][]int
+}
+
+func NewLines(w, h int) *Lines {
+	l := &Lines{
+		Width:     w,
+		Height:    h,
+		cellLines: make([][]int, w*h),
+	}
+	for d, ls := range [][][]Point{HorizontalLines(w, h), VerticalLines(w, h), RightDownLines(w, h), RightUpLines(w, h)} {
+		for _, points := range ls {
+			// nothing to match on a too short diagonal
+			if len(points) < 3 {
+				continue
+			}
+			for _, p := range points {
+				l.cellLines[p.y*w+p.x] = append(l.cellLines[p.y*w+p.x], len(l.Lines))
+			}
+			l.Lines = append(l.Lines, Line{Points: points, Direction: Direction(d)})
+		}
+	}
+	return l
+}
+
+// At returns indices of lines passing through cx, cy.
+func (l *Lines) At(cx, cy int) []int {
+	if 0 <= cx && cx < l.Width && 0 <= cy && cy < l.Height {
+		return l.cellLines[cy*l.Width+cx]
+	}
+	return nil
+}
+
+var BoardLines = NewLines(BoardWidth, BoardHeight)
+
+func (b *Board) ColorAt(cx, cy int) Color {
+	if c, ok := b.At(cx, cy); ok && *c != nil {
+		return (*c).Color
+	}
+	return None
+}
+
+// MarkErase checks every line of the board.
+func (b *Board) MarkErase() MatchResult {
+	r := b.markLines(func(int) bool { return true })
+	if len(r.Groups) == 0 {
+		b.Settle()
+	}
+	return r
+}
+
+// MarkChanged works like MarkErase but only checks lines through cells
+// changed since the last state without any match.
+func (b *Board) MarkChanged() MatchResult {
+	dirty := make([]bool, len(BoardLines.Lines))
+	for cx := 0; cx < BoardWidth; cx++ {
+		for cy := 0; cy < BoardHeight; cy++ {
+			if b.ColorAt(cx, cy) != b.stable[cx][cy] {
+				for _, li := range BoardLines.At(cx, cy) {
+					dirty[li] = true
+				}
+			}
+		}
+	}
+	r := b.markLines(func(li int) bool { return dirty[li] })
+	if len(r.Groups) == 0 {
+		b.Settle()
+	}
+	return r
+}
+
+// Settle remembers the current colors as a state without any match.
+func (b *Board) Settle() {
+	for cx := 0; cx < BoardWidth; cx++ {
+		for cy := 0; cy < BoardHeight; cy++ {
+			b.stable[cx][cy] = b.ColorAt(cx, cy)
+		}
+	}
+}
+
+func (b *Board) markLines(check func(li int) bool) MatchResult {
+	var r MatchResult
+	for li, line := range BoardLines.Lines {
+		if !check(li) {
+			continue
+		}
+		points := line.Points
+		sequent := 0
+		for i := 1; i <= len(points); i++ {
+			c := b.ColorAt(points[sequent].x, points[sequent].y)
+			if i < len(points) && c == b.ColorAt(points[i].x, points[i].y) && c.Colored() {
+				continue
+			}
+			if i-sequent >= 3 {
+				m := MatchGroup{Color: c, Direction: line.Direction}
+				for _, cp := range points[sequent:i] {
+					if b.MarkEraseAt(cp.x, cp.y) {
+						m.Cells = append(m.Cells, cp)
+					}
+				}
+				r.Groups = append(r.Groups, m)
+			}
+			sequent = i
+		}
+	}
+	for cy := 0; cy < BoardHeight; cy++ {
+		for cx := 0; cx < BoardWidth; cx++ {
+			if c, ok := b.At(cx, cy); ok && *c != nil && (*c).Color == Jammer && (*c).Erased {
+				r.Jammers = append(r.Jammers, Point{cx, cy})
+			}
+		}
+	}
+	return r
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBoardMarkEraseGroups(t *testing.T) {
+	type C struct {
+		x, y int
+		c    Color
+	}
+	type Case struct {
+		t       string
+		c       []C
+		groups  []MatchGroup
+		jammers []Point
+	}
+	cases := []Case{
+		Case{
+			"horizontal",
+			[]C{
+				C{1, 1, Red},
+				C{2, 1, Red},
+				C{3, 1, Red},
+				C{4, 1, Green},
+			},
+			[]MatchGroup{
+				MatchGroup{[]Point{{1, 1}, {2, 1}, {3, 1}}, Red, Horizontal},
+			},
+			nil,
+		},
+		Case{
+			"vertical four",
+			[]C{
+				C{2, 1, Blue},
+				C{2, 2, Blue},
+				C{2, 3, Blue},
+				C{2, 4, Blue},
+			},
+			[]MatchGroup{
+				MatchGroup{[]Point{{2, 1}, {2, 2}, {2, 3}, {2, 4}}, Blue, Vertical},
+			},
+			nil,
+		},
+		Case{
+			"right down and up",
+			[]C{
+				C{1, 1, Green},
+				C{2, 2, Green},
+				C{3, 3, Green},
+				C{4, 4, Yellow},
+				C{5, 3, Yellow},
+				C{6, 2, Yellow},
+			},
+			[]MatchGroup{
+				MatchGroup{[]Point{{1, 1}, {2, 2}, {3, 3}}, Green, RightDown},
+				MatchGroup{[]Point{{4, 4}, {5, 3}, {6, 2}}, Yellow, RightUp},
+			},
+			nil,
+		},
+		Case{
+			"jammer",
+			[]C{
+				C{1, 3, Red},
+				C{2, 3, Red},
+				C{3, 3, Red},
+				C{4, 3, Jammer},
+				C{2, 4, Jammer},
+			},
+			[]MatchGroup{
+				MatchGroup{[]Point{{1, 3}, {2, 3}, {3, 3}}, Red, Horizontal},
+			},
+			[]Point{{4, 3}, {2, 4}},
+		},
+	}
+	for _, cs := range cases {
+		b := NewBoard()
+		for _, c := range cs.c {
+			if cell, ok := b.At(c.x, c.y); ok {
+				(*cell) = &Stone{Color: c.c}
+			}
+		}
+		r := b.MarkErase()
+		if !reflect.DeepEqual(r.Groups, cs.groups) {
+			t.Error(cs.t, "groups", r.Groups, cs.groups)
+		}
+		if !reflect.DeepEqual(r.Jammers, cs.jammers) {
+			t.Error(cs.t, "jammers", r.Jammers, cs.jammers)
+		}
+		num := 0
+		for _, m := range cs.groups {
+			num += m.Len()
+		}
+		if r.Num() != num {
+			t.Error(cs.t, "num", r.Num(), num)
+		}
+	}
+}
+
+func TestMatchResultStones(t *testing.T) {
+	r := MatchResult{
+		Groups: []MatchGroup{
+			MatchGroup{[]Point{{1, 3}, {2, 3}, {3, 3}}, Red, Horizontal},
+			MatchGroup{[]Point{{2, 1}, {2, 2}, {2, 3}}, Red, Vertical},
+		},
+		Jammers: []Point{{4, 3}},
+	}
+	if r.Stones() != 5 {
+		t.Error("stones", r.Stones(), 5)
+	}
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+const (
+	MenuX          = 24
+	MenuY          = 48
+	MenuItemHeight = 20
+)
+
+type MenuItem struct {
+	Label  string
+	Action func(g *Game)
+}
+
+type Menu struct {
+	Title string
+	Items []MenuItem
+	// item chosen by keys, shown once a key is pressed
+	Cursor   int
+	Keyboard bool
+	// Adjust changes item i by d when left or right is pressed
+	Adjust func(g *Game, i, d int)
+	// Capture takes the next key pressed instead of the menu
+	Capture func(g *Game, k ebiten.Key)
+}
+
+var ConfirmKeys []ebiten.Key = []ebiten.Key{ebiten.KeyEnter, ebiten.KeySpace}
+
+func KeyJustPressed(keys ...ebiten.Key) bool {
+	for _, k := range keys {
+		if inpututil.IsKeyJustPressed(k) {
+			return true
+		}
+	}
+	return false
+}
+
+// JustPressed returns where the mouse or a touch has just pressed.
+func JustPressed() (Point, bool) {
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		x, y := CursorPosition()
+		return Point{x, y}, true
+	}
+	for _, tid := range inpututil.JustPressedTouchIDs() {
+		x, y := TouchPosition(tid)
+		return Point{x, y}, true
+	}
+	return Point{}, false
+}
+
+func (m *Menu) ItemAt(x, y int) int {
+	x, y = x-Viewport.Left(), y-Viewport.Top()
+	if x < MenuX-8 || y < MenuY {
+		return -1
+	}
+	if i := (y - MenuY) / MenuItemHeight; i < len(m.Items) {
+		return i
+	}
+	return -1
+}
+
+// MoveCursor moves the cursor by d to the next item with an action.
+func (m *Menu) MoveCursor(d int) {
+	for i := 1; i <= len(m.Items); i++ {
+		c := ((m.Cursor+d*i)%len(m.Items) + len(m.Items)) % len(m.Items)
+		if m.Items[c].Action != nil {
+			m.Cursor = c
+			return
+		}
+	}
+}
+
+func (m *Menu) Update(g *Game) {
+	if p, ok := JustPressed(); ok {
+		if i := m.ItemAt(p.x, p.y); i >= 0 && m.Items[i].Action != nil {
+			m.Items[i].Action(g)
+		}
+		return
+	}
+	if m.Capture != nil {
+		if k, ok := JustPressedKey(); ok {
+			m.Capture(g, k)
+		}
+		return
+	}
+	switch {
+	case KeyJustPressed(ebiten.KeyUp):
+		if m.Keyboard {
+			m.MoveCursor(-1)
+		}
+	case KeyJustPressed(ebiten.KeyDown):
+		if m.Keyboard {
+			m.MoveCursor(1)
+		}
+	case KeyJustPressed(ebiten.KeyLeft, ebiten.KeyRight):
+		if m.Keyboard && m.Adjust != nil {
+			d := 1
+			if KeyJustPressed(ebiten.KeyLeft) {
+				d = -1
+			}
+			m.Adjust(g, m.Cursor, d)
+			return
+		}
+	case KeyJustPressed(ConfirmKeys...):
+		if m.Keyboard && m.Items[m.Cursor].Action != nil {
+			m.Items[m.Cursor].Action(g)
+		}
+	default:
+		return
+	}
+	if !m.Keyboard {
+		m.Keyboard = true
+		if m.Items[m.Cursor].Action == nil {
+			m.MoveCursor(1)
+		}
+	}
+}
+
+func (m *Menu) Draw(r *ebiten.Image) {
+	x, y := Viewport.Left()+MenuX, Viewport.Top()+MenuY
+	DrawText(r, m.Title, x-8, y-MenuItemHeight)
+	for i, item := range m.Items {
+		DrawText(r, item.Label, x, y+i*MenuItemHeight)
+	}
+	if m.Keyboard {
+		DrawText(r, ">", x-8, y+m.Cursor*MenuItemHeight)
+	}
+}
+
+func (g *Game) OpenMenu(m *Menu) {
+	// keep on choosing by keys when opened by a key
+	if KeyJustPressed(ConfirmKeys...) {
+		m.Keyboard = true
+	}
+	if m.Items[m.Cursor].Action == nil {
+		m.MoveCursor(1)
+	}
+	g.Menu = m
+	g.Step = Select
+}
+
+func MainMenu() *Menu {
+	return &Menu{
+		Title: "cut'n'align",
+		Items: []MenuItem{
+			{T("Tutorial"), func(g *Game) { g.Start(NewTutorial(0)) }},
+			{T("Endless"), func(g *Game) { g.Start(Endless{}) }},
+			{T("Zen"), func(g *Game) { g.Start(Zen{}) }},
+			{T("Daily"), func(g *Game) { g.OpenMenu(DailyMenu(Today())) }},
+			{T("Attack"), func(g *Game) { g.OpenMenu(AttackMenu()) }},
+			{T("Puzzle"), func(g *Game) { g.OpenMenu(PuzzleMenu()) }},
+			{T("Editor"), func(g *Game) { g.OpenEditor(NewEditor()) }},
+			{T("Records"), func(g *Game) { g.OpenMenu(RecordsMenu()) }},
+			{T("Stats"), func(g *Game) { g.OpenMenu(StatsMenu()) }},
+			{T("Achievements"), func(g *Game) { g.OpenMenu(AchievementMenu(g)) }},
+			{T("Settings"), func(g *Game) { g.OpenMenu(SettingsMenu()) }},
+		},
+	}
+}
+
+func PuzzleMenu() *Menu {
+	m := &Menu{Title: T("Puzzle")}
+	for _, s := range Stages {
+		s := s
+		m.Items = append(m.Items, MenuItem{s.Name, func(g *Game) { g.Start(NewPuzzleMode(s)) }})
+	}
+	m.Items = append(m.Items, MenuItem{T("Back"), func(g *Game) { g.OpenMenu(MainMenu()) }})
+	return m
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Mode is a rule set played on top of the Step machine.
+type Mode interface {
+	// Start sets up the board after it is initialized, before the pick is reserved.
+	Start(g *Game)
+	// Next returns the next stone to pick, or nil when there is no more.
+	Next(g *Game) *Stone
+	// TurnEnd runs when the board settled after a cut and returns the next step.
+	TurnEnd(g *Game) Step
+	// Expired reports whether the game ends while waiting for a cut, e.g. by time,
+	// and the step it ends at.
+	Expired(g *Game) (Step, bool)
+	// Draw draws mode specific HUD.
+	Draw(g *Game, r *ebiten.Image)
+}
+
+// Endless is the original LD44 rule, played until the board is full.
+type Endless struct{}
+
+func (Endless) Start(g *Game) {}
+
+func (Endless) Next(g *Game) *Stone {
+	return DealColors(g, ColorLevel(g.Turn))
+}
+
+// DealColors deals the first level colors in shuffled rounds.
+func DealColors(g *Game, level int) *Stone {
+	if len(g.Buffer) == 0 {
+		colors := []Color{Red, Blue, Green, Yellow, Pink, Orange}[:level]
+		g.Rand.Shuffle(len(colors), func(i, j int) {
+			colors[i], colors[j] = colors[j], colors[i]
+		})
+		g.Buffer = colors
+	}
+	var c Color
+	c, g.Buffer = g.Buffer[0], g.Buffer[1:]
+	return &Stone{Color: c}
+}
+
+// ColorLevel is the number of colors dealt at turn, one more every 24 turns up to six.
+func ColorLevel(turn int) int {
+	level := 3
+	if turn > 24 {
+		level++
+	}
+	if turn > 48 {
+		level++
+	}
+	if turn > 72 {
+		level++
+	}
+	return level
+}
+
+func (Endless) Level(g *Game) int {
+	return ColorLevel(g.Turn)
+}
+
+func (Endless) TurnEnd(g *Game) Step {
+	if g.Turn%JammerTurn == 0 {
+		g.CauseJammer()
+	}
+	g.ReservePick()
+	if !g.HasLegalMove() {
+		return GameOver
+	}
+	return Move
+}
+
+func (Endless) Expired(g *Game) (Step, bool) {
+	return Move, false
+}
+
+func (Endless) Draw(g *Game, r *ebiten.Image) {}
+
+func (Endless) Name() string {
+	return "Endless"
+}
+
+func (Endless) Record(g *Game) (Record, bool) {
+	return Record{Score: g.Score, Turns: g.Turn, Frames: g.Frames}, true
+}
+
+func (Endless) Better(a, b Record) bool {
+	return a.Score > b.Score
+}
+
+func (Endless) Format(r Record) string {
+	return strconv.Itoa(r.Score)
+}
+
+// quit button at the top of the free column
+const QuitY = 2
+
+// QuitPressed reports whether the player quits by the key or the quit button.
+func QuitPressed(g *Game) bool {
+	if ActionJustPressed(ActQuit) {
+		return true
+	}
+	p, ok := JustPressed()
+	return ok && g.Board.Beside(p.x) && p.y < Viewport.Top()+HUDTop
+}
+
+// DrawQuit draws the quit button while waiting for a cut.
+func DrawQuit(g *Game, r *ebiten.Image) {
+	if g.Step == Move {
+		x := Viewport.Left() + 4
+		if !Setting.LeftHanded {
+			x = g.Board.OriginX + BoardWidth*StoneWidth - 6
+		}
+		DrawText(r, T("QUIT"), x, Viewport.Top()+QuitY)
+	}
+}
+
+// Real reports whether the game counts for achievements and lifetime stats,
+// a Ranked game and not a lesson, a puzzle or a test play.
+func (g *Game) Real() bool {
+	if _, ok := g.Mode.(Guided); ok {
+		return false
+	}
+	if _, ok := g.Mode.(*PuzzleMode); ok {
+		return false
+	}
+	_, ok := g.Mode.(Ranked)
+	return ok
+}
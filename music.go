@@ -0,0 +1,157 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+)
+
+const (
+	// frames a layer takes to fade all the way in or out
+	FadeFrames = TPS
+	// average height the board starts to feel dangerous at, as the noise of the stones
+	DangerHeight = 8.0
+	// chain length the chain layer is at full at
+	ChainMood = 4
+	MinColors = 3
+	MaxColors = 6
+	// the recorded loops are four bars of 4/4, the made layers keep their beat
+	LoopBeats = 16
+)
+
+// Mood is the state of the game the music follows, each from 0 to 1.
+// Out of a game only the calm track plays.
+type Mood struct {
+	Playing bool
+	Danger  float64
+	Colors  float64
+	Chain   float64
+}
+
+// Drive is whether the driven track plays instead of the calm one.
+// The BGM variants keep to one of them.
+func (m Mood) Drive() float64 {
+	switch Cosmetic.BGM {
+	case BGMCalm:
+		return 0
+	case BGMDrive:
+		return 1
+	}
+	if m.Playing {
+		return 1
+	}
+	return 0
+}
+
+func clamp01(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
+
+// Mood reads the danger, the colors on the board and the chain of the game.
+func (g *Game) Mood() Mood {
+	var m Mood
+	switch g.Step {
+	case Move, FallStone, WaitErase, CauseJammer:
+		m.Playing = true
+	default:
+		return m
+	}
+	m.Danger = clamp01((DangerHeight - g.HeightAverage()) / DangerHeight)
+	colors := map[Color]bool{}
+	for x := 1; x < BoardWidth-1; x++ {
+		for y := 0; y < BoardHeight; y++ {
+			if c, ok := g.Board.At(x, y); ok && *c != nil && (*c).Colored() {
+				colors[(*c).Color] = true
+			}
+		}
+	}
+	m.Colors = clamp01(float64(len(colors)-MinColors) / (MaxColors - MinColors))
+	m.Chain = clamp01(float64(g.SequentErase) / ChainMood)
+	return m
+}
+
+// Layer is a stem of the music, recorded in File or made by Synth for a loop of frames.
+// All layers loop in step, each fading to its Level.
+type Layer struct {
+	File  string
+	Synth func(frames int) []byte
+	Level func(m Mood) float64
+	// the gain faded to so far
+	Gain float64
+	// nil without audio
+	Voice *Voice
+}
+
+// MusicLayers are the calm and driven tracks, and a layer over them for every part of the mood.
+var MusicLayers []*Layer = []*Layer{
+	{File: "asset/bgm_off.ogg", Level: func(m Mood) float64 { return 1 - m.Drive() }},
+	{File: "asset/bgm.ogg", Level: func(m Mood) float64 { return m.Drive() }},
+	{Synth: HeartbeatStem, Level: func(m Mood) float64 { return m.Danger }},
+	{Synth: ShakerStem, Level: func(m Mood) float64 { return m.Colors }},
+	{Synth: HatStem, Level: func(m Mood) float64 { return m.Chain }},
+}
+
+// HeartbeatStem beats twice on every beat, for the danger.
+func HeartbeatStem(frames int) []byte {
+	return Pattern(frames, LoopBeats*4, []float64{1, 0.6, 0, 0}, func(t float64) float64 {
+		return sine(55-40*t, t) * math.Exp(-t*14)
+	})
+}
+
+// ShakerStem shakes on the eighths, stronger off the beat, for the colors.
+func ShakerStem(frames int) []byte {
+	r := rand.New(rand.NewSource(2))
+	return Pattern(frames, LoopBeats*2, []float64{0.3, 0.6}, func(t float64) float64 {
+		return (r.Float64()*2 - 1) * math.Exp(-t*40)
+	})
+}
+
+// HatStem ticks on the sixteenths, for the chain.
+func HatStem(frames int) []byte {
+	r := rand.New(rand.NewSource(3))
+	last := 0.0
+	return Pattern(frames, LoopBeats*4, []float64{0.5, 0.25, 0.7, 0.25}, func(t float64) float64 {
+		// the difference of noise keeps the highs
+		n := r.Float64()*2 - 1
+		v := n - last
+		last = n
+		return v * 0.5 * math.Exp(-t*90)
+	})
+}
+
+// Fade moves from toward to by a step of FadeFrames.
+func Fade(from, to float64) float64 {
+	step := 1.0 / FadeFrames
+	if math.Abs(to-from) <= step {
+		return to
+	}
+	return from + math.Copysign(step, to-from)
+}
+
+// StartMusic plays all layers together from the start at the levels of the game.
+func (g *Game) StartMusic() {
+	m := g.Mood()
+	for _, l := range MusicLayers {
+		l.Gain = l.Level(m)
+		if l.Voice != nil {
+			MusicChannel.SetGain(l.Voice, l.Gain)
+			l.Voice.Player.Seek(0)
+		}
+	}
+	for _, l := range MusicLayers {
+		if l.Voice != nil {
+			l.Voice.Player.Play()
+		}
+	}
+}
+
+// UpdateMusic fades the layers a frame toward the levels of the game.
+// Silent layers keep playing to stay in step.
+func (g *Game) UpdateMusic() {
+	m := g.Mood()
+	for _, l := range MusicLayers {
+		l.Gain = Fade(l.Gain, l.Level(m))
+		if l.Voice != nil {
+			MusicChannel.SetGain(l.Voice, l.Gain)
+		}
+	}
+}
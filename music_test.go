@@ -0,0 +1,128 @@
+package main
+
+import "testing"
+
+func TestFade(t *testing.T) {
+	type Case struct {
+		t            string
+		from, to, fd float64
+	}
+	step := 1.0 / FadeFrames
+	cases := []Case{
+		Case{"in", 0, 1, step},
+		Case{"out", 1, 0, 1 - step},
+		Case{"reached", 0.5, 0.5 + step/2, 0.5 + step/2},
+		Case{"still", 0.3, 0.3, 0.3},
+	}
+	for _, cs := range cases {
+		if f := Fade(cs.from, cs.to); f != cs.fd {
+			t.Error(cs.t, f, cs.fd)
+		}
+	}
+}
+
+func TestMood(t *testing.T) {
+	type Case struct {
+		t     string
+		cells []StageCell
+		chain int
+		mood  Mood
+	}
+	var tower []StageCell
+	for y := 0; y < BoardHeight; y++ {
+		for x := 1; x < BoardWidth-1; x++ {
+			tower = append(tower, StageCell{x, y, []Color{Red, Blue, Green, Yellow, Pink, Orange}[(x+y)%6]})
+		}
+	}
+	cases := []Case{
+		Case{"empty", nil, 0, Mood{true, 0, 0, 0}},
+		Case{"full", tower, 0, Mood{true, 1, 1, 0}},
+		Case{"chain", []StageCell{{1, 15, Red}, {2, 15, Blue}, {3, 15, Green}, {4, 15, Yellow}}, 2, Mood{true, 0, 1.0 / 3, 0.5}},
+	}
+	for _, cs := range cases {
+		g := NewGame()
+		g.Start(NewPuzzleMode(&Stage{Name: cs.t, Cells: cs.cells}))
+		g.SequentErase = cs.chain
+		if m := g.Mood(); m != cs.mood {
+			t.Error(cs.t, m, cs.mood)
+		}
+	}
+	g := NewGame()
+	g.Initialize()
+	if g.Mood().Playing {
+		t.Error("playing on the title")
+	}
+}
+
+func TestMusicLevels(t *testing.T) {
+	defer func(c Cosmetics) { Cosmetic = c }(Cosmetic)
+	type Case struct {
+		t      string
+		bgm    string
+		mood   Mood
+		levels []float64
+	}
+	// calm, driven, danger, colors, chain
+	cases := []Case{
+		Case{"title", BGMStandard, Mood{}, []float64{1, 0, 0, 0, 0}},
+		Case{"calm board", BGMStandard, Mood{Playing: true}, []float64{0, 1, 0, 0, 0}},
+		Case{"danger", BGMStandard, Mood{true, 1, 0, 0}, []float64{0, 1, 1, 0, 0}},
+		Case{"colors", BGMStandard, Mood{true, 0, 0.5, 0}, []float64{0, 1, 0, 0.5, 0}},
+		Case{"chain", BGMStandard, Mood{true, 0, 0, 0.25}, []float64{0, 1, 0, 0, 0.25}},
+		Case{"calm variant", BGMCalm, Mood{true, 1, 1, 1}, []float64{1, 0, 1, 1, 1}},
+		Case{"drive variant", BGMDrive, Mood{}, []float64{0, 1, 0, 0, 0}},
+	}
+	for _, cs := range cases {
+		Cosmetic.BGM = cs.bgm
+		for i, l := range MusicLayers {
+			if v := l.Level(cs.mood); v != cs.levels[i] {
+				t.Error(cs.t, i, v, cs.levels[i])
+			}
+		}
+	}
+}
+
+func TestStems(t *testing.T) {
+	frames := SampleRate
+	for _, l := range MusicLayers {
+		if l.Synth == nil {
+			continue
+		}
+		if data := l.Synth(frames); len(data) != frames*4 {
+			t.Error("stem length", len(data)/4, frames)
+		}
+	}
+	data := Pattern(frames, 4, []float64{1, 0}, func(t float64) float64 { return 1 })
+	type Case struct {
+		frame int
+		loud  bool
+	}
+	for _, cs := range []Case{{0, true}, {frames / 4, false}, {frames / 2, true}, {frames - 1, false}} {
+		if loud := data[cs.frame*4] != 0 || data[cs.frame*4+1] != 0; loud != cs.loud {
+			t.Error("pattern", cs.frame, loud)
+		}
+	}
+}
+
+func TestMusicCrossfade(t *testing.T) {
+	g := NewGame()
+	g.Initialize()
+	g.StartMusic()
+	if MusicLayers[0].Gain != 1 || MusicLayers[1].Gain != 0 {
+		t.Error("title", MusicLayers[0].Gain, MusicLayers[1].Gain)
+	}
+	g.Start(Endless{})
+	g.UpdateMusic()
+	if MusicLayers[0].Gain != 1-1.0/FadeFrames || MusicLayers[1].Gain != 1.0/FadeFrames {
+		t.Error("fading", MusicLayers[0].Gain, MusicLayers[1].Gain)
+	}
+	for i := 0; i < FadeFrames; i++ {
+		g.UpdateMusic()
+	}
+	m := g.Mood()
+	for i, l := range MusicLayers {
+		if l.Gain != l.Level(m) {
+			t.Error("faded", i, l.Gain, l.Level(m))
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Palette recolors the six stone colors, keeping the shading of the texture.
+// Classic has no colors and leaves the texture as is.
+type Palette struct {
+	Name   string
+	Colors map[Color]color.RGBA
+}
+
+const ClassicPalette = "Classic"
+
+// Palettes keep the colors apart in lightness as well as in hue
+// for each kind of color blindness.
+var Palettes []Palette = []Palette{
+	{ClassicPalette, nil},
+	{"Deuteranopia", map[Color]color.RGBA{
+		Red:    {230, 97, 1, 0xff},
+		Blue:   {0, 90, 181, 0xff},
+		Green:  {153, 204, 255, 0xff},
+		Yellow: {255, 225, 90, 0xff},
+		Pink:   {220, 50, 130, 0xff},
+		Orange: {120, 90, 40, 0xff},
+	}},
+	{"Protanopia", map[Color]color.RGBA{
+		Red:    {255, 130, 30, 0xff},
+		Blue:   {30, 60, 200, 0xff},
+		Green:  {100, 200, 240, 0xff},
+		Yellow: {250, 240, 120, 0xff},
+		Pink:   {190, 110, 220, 0xff},
+		Orange: {90, 70, 40, 0xff},
+	}},
+	{"Tritanopia", map[Color]color.RGBA{
+		Red:    {220, 40, 40, 0xff},
+		Blue:   {0, 130, 140, 0xff},
+		Green:  {120, 220, 170, 0xff},
+		Yellow: {255, 190, 200, 0xff},
+		Pink:   {140, 0, 80, 0xff},
+		Orange: {235, 235, 235, 0xff},
+	}},
+}
+
+func PaletteNames() []string {
+	var names []string
+	for _, p := range Palettes {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+func FindPalette(name string) Palette {
+	for _, p := range Palettes {
+		if p.Name == name {
+			return p
+		}
+	}
+	return Palettes[0]
+}
+
+// Shape tells whether x, y in -1 to 1 is inside.
+type Shape func(x, y float64) bool
+
+// Shapes mark each color so that it is told apart without its color.
+var Shapes map[Color]Shape = map[Color]Shape{
+	Red: func(x, y float64) bool {
+		return x*x+y*y < 0.25
+	},
+	Blue: func(x, y float64) bool {
+		return math.Abs(x) < 0.45 && math.Abs(y) < 0.45
+	},
+	Green: func(x, y float64) bool {
+		return y < 0.4 && math.Abs(x)*2 < y+0.5
+	},
+	Yellow: func(x, y float64) bool {
+		return math.Abs(x)+math.Abs(y) < 0.6
+	},
+	Pink: func(x, y float64) bool {
+		return (math.Abs(x) < 0.15 || math.Abs(y) < 0.15) && math.Abs(x) < 0.55 && math.Abs(y) < 0.55
+	},
+	Orange: func(x, y float64) bool {
+		return math.Abs(math.Abs(x)-math.Abs(y)) < 0.2 && math.Abs(x) < 0.5
+	},
+}
+
+var ShapeColor color.RGBA = color.RGBA{0, 0, 0, 0xb0}
+
+// TintTile recolors tile by c, scaled by the lightness of each pixel
+// so that the brightest pixel gets c.
+func TintTile(tile image.Image, c color.RGBA) *image.RGBA {
+	b := tile.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	lum := func(x, y int) (float64, uint8) {
+		n := color.NRGBAModel.Convert(tile.At(x, y)).(color.NRGBA)
+		return (0.299*float64(n.R) + 0.587*float64(n.G) + 0.114*float64(n.B)) / 0xff, n.A
+	}
+	top := 0.0
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if l, a := lum(x, y); a > 0 {
+				top = math.Max(top, l)
+			}
+		}
+	}
+	if top == 0 {
+		top = 1
+	}
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			l, a := lum(x, y)
+			f := l / top
+			dst.Set(x-b.Min.X, y-b.Min.Y, color.NRGBA{
+				uint8(float64(c.R) * f), uint8(float64(c.G) * f), uint8(float64(c.B) * f), a})
+		}
+	}
+	return dst
+}
+
+// StampShape draws shape on the middle of img.
+func StampShape(img *image.RGBA, shape Shape) {
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			// the center of the pixel
+			sx := (float64(x-b.Min.X)+0.5)/float64(b.Dx())*2 - 1
+			sy := (float64(y-b.Min.Y)+0.5)/float64(b.Dy())*2 - 1
+			if shape(sx, sy) {
+				draw.Draw(img, image.Rect(x, y, x+1, y+1), image.NewUniform(ShapeColor), image.Point{}, draw.Over)
+			}
+		}
+	}
+}
+
+// StoneTile makes the image of a colored stone from r of the texture.
+func StoneTile(texture image.Image, r image.Rectangle, c Color, p Palette, shapes bool) *image.RGBA {
+	tile := texture.(interface {
+		SubImage(r image.Rectangle) image.Image
+	}).SubImage(r)
+	var img *image.RGBA
+	if pc, ok := p.Colors[c]; ok {
+		img = TintTile(tile, pc)
+	} else {
+		img = image.NewRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+		draw.Draw(img, img.Bounds(), tile, r.Min, draw.Src)
+	}
+	if shape, ok := Shapes[c]; ok && shapes {
+		StampShape(img, shape)
+	}
+	return img
+}
+
+var (
+	// decoded atlas of the theme, to make stones from
+	TextureSource image.Image
+	// stones cut from the atlas as is
+	ClassicStones map[Color]*ebiten.Image
+	stoneSets     map[string]map[Color]*ebiten.Image = map[string]map[Color]*ebiten.Image{}
+)
+
+// UseStones swaps StoneImages for stones in the palette, marked by shapes if asked.
+func UseStones(palette string, shapes bool) {
+	p := FindPalette(palette)
+	if p.Name == ClassicPalette && !shapes {
+		StoneImages = ClassicStones
+		return
+	}
+	key := p.Name
+	if shapes {
+		key += "/shapes"
+	}
+	if set, ok := stoneSets[key]; ok {
+		StoneImages = set
+		return
+	}
+	set := map[Color]*ebiten.Image{}
+	for c, img := range ClassicStones {
+		set[c] = img
+		if c.Colored() {
+			set[c] = ebiten.NewImageFromImage(StoneTile(TextureSource, CurrentTheme.Stones.Rect(int(c)), c, p, shapes))
+		}
+	}
+	stoneSets[key] = set
+	StoneImages = set
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestTintTile(t *testing.T) {
+	tile := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+	tile.Set(0, 0, color.NRGBA{200, 200, 200, 0xff})
+	tile.Set(1, 0, color.NRGBA{100, 100, 100, 0x80})
+	img := TintTile(tile, color.RGBA{0, 100, 200, 0xff})
+	if c := color.NRGBAModel.Convert(img.At(0, 0)).(color.NRGBA); c != (color.NRGBA{0, 100, 200, 0xff}) {
+		t.Error("brightest", c)
+	}
+	c := color.NRGBAModel.Convert(img.At(1, 0)).(color.NRGBA)
+	if c.A != 0x80 || c.B < 98 || c.B > 102 {
+		t.Error("shaded", c)
+	}
+}
+
+func TestShapes(t *testing.T) {
+	masks := map[string]Color{}
+	for _, c := range []Color{Red, Blue, Green, Yellow, Pink, Orange} {
+		img := image.NewRGBA(image.Rect(0, 0, StoneWidth, StoneHeight))
+		StampShape(img, Shapes[c])
+		mask := ""
+		for y := 0; y < StoneHeight; y++ {
+			for x := 0; x < StoneWidth; x++ {
+				if _, _, _, a := img.At(x, y).RGBA(); a > 0 {
+					mask += "#"
+				} else {
+					mask += "."
+				}
+			}
+		}
+		if _, _, _, a := img.At(StoneWidth/2, StoneHeight/2).RGBA(); a == 0 {
+			t.Error(c, "center")
+		}
+		if _, _, _, a := img.At(0, 0).RGBA(); a != 0 {
+			t.Error(c, "corner")
+		}
+		if o, ok := masks[mask]; ok {
+			t.Error(c, "same as", o)
+		}
+		masks[mask] = c
+	}
+}
+
+func TestPalettes(t *testing.T) {
+	for _, p := range Palettes[1:] {
+		seen := map[color.RGBA]Color{}
+		for _, c := range []Color{Red, Blue, Green, Yellow, Pink, Orange} {
+			pc, ok := p.Colors[c]
+			if !ok {
+				t.Error(p.Name, c, "missing")
+			}
+			if o, ok := seen[pc]; ok {
+				t.Error(p.Name, c, "same as", o)
+			}
+			seen[pc] = c
+		}
+	}
+	if p := FindPalette("nothing"); p.Name != ClassicPalette {
+		t.Error("fallback", p.Name)
+	}
+}
+
+func TestUseStones(t *testing.T) {
+	defer ApplySettings(Setting)
+	s := Setting
+	s.Palette, s.Shapes = "Tritanopia", true
+	ApplySettings(s)
+	if StoneImages[Red] == ClassicStones[Red] {
+		t.Error("red not swapped")
+	}
+	if StoneImages[Jammer] != ClassicStones[Jammer] {
+		t.Error("jammer swapped")
+	}
+	set := StoneImages
+	s.Palette, s.Shapes = ClassicPalette, false
+	ApplySettings(s)
+	if StoneImages[Red] != ClassicStones[Red] {
+		t.Error("classic")
+	}
+	s.Palette, s.Shapes = "Tritanopia", true
+	ApplySettings(s)
+	if StoneImages[Red] != set[Red] {
+		t.Error("not cached")
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	// a view this wide has room for the panel beside the board
+	LandscapeWidth = 320
+
+	PanelWidth = 7 * NumberWidth
+	PanelGap   = 8
+
+	// rows of the panel, from the top of the view
+	PanelChainY     = 0
+	PanelScoreY     = 48
+	PanelCountdownY = 132
+	PanelLevelY     = 184
+	PanelNextY      = 204
+)
+
+// Landscape views show the score and counters upright in a panel beside the board,
+// instead of rotated down the right edge.
+func (v View) Landscape() bool {
+	return v.Width >= LandscapeWidth
+}
+
+// PanelX is the left of the panel, on the free side of the board.
+func (b *Board) PanelX() int {
+	if Setting.LeftHanded {
+		return b.OriginX - PanelGap - PanelWidth
+	}
+	return b.OriginX + BoardWidth*StoneWidth + PanelGap
+}
+
+// HUDX is the right of the rotated numbers of portrait views,
+// which are drawn NumberWidth left of it, NumberHeight wide.
+// They keep to the edge of the view on the free side of the board.
+func (b *Board) HUDX() int {
+	if Setting.LeftHanded {
+		return 2*NumberWidth + NumberHeight
+	}
+	return Viewport.Width
+}
+
+// Countdown is implemented by modes that count down to their end.
+type Countdown interface {
+	Countdown(g *Game) (label string, n int)
+}
+
+// RenderNumberText draws the characters of an equation upright from x, y at left top.
+func RenderNumberText(r *ebiten.Image, text string, x, y int) {
+	for i, c := range text {
+		opt := SpriteOptions()
+		opt.GeoM.Translate(float64(x+i*NumberWidth), float64(y))
+		Blit(r, NumberImages[NumberSprite(c)], opt)
+	}
+}
+
+// DrawPanel draws the upright HUD of landscape views.
+func (g *Game) DrawPanel(r *ebiten.Image) {
+	x, top := g.Board.PanelX(), Viewport.Top()
+	if g.Step == Title {
+		// below the title letters
+		DrawText(r, T("HIGH SCORE"), x, top+PanelCountdownY)
+		RenderNumberText(r, strconv.Itoa(g.HighScore), x, top+PanelCountdownY+16)
+		return
+	}
+	if g.SequentErase > 0 {
+		DrawText(r, T("CHAIN"), x, top+PanelChainY)
+		RenderNumberText(r, strconv.Itoa(g.SequentErase), x, top+PanelChainY+16)
+	}
+	DrawText(r, T("SCORE"), x, top+PanelScoreY)
+	if g.SequentErase > 0 {
+		for i, line := range SplitEquation(g.ScoreEquation, PanelWidth/NumberWidth) {
+			if i < 2 {
+				RenderNumberText(r, line, x, top+PanelScoreY+16+i*NumberHeight)
+			}
+		}
+	} else {
+		RenderNumberText(r, strconv.Itoa(g.Score), x, top+PanelScoreY+16)
+	}
+	if m, ok := g.Mode.(Countdown); ok {
+		label, n := m.Countdown(g)
+		DrawText(r, label, x, top+PanelCountdownY)
+		RenderNumberText(r, strconv.Itoa(n), x, top+PanelCountdownY+16)
+	}
+	if m, ok := g.Mode.(Leveled); ok {
+		DrawText(r, Tf("LEVEL %d", m.Level(g)), x, top+PanelLevelY)
+	}
+	if len(g.Pick) > g.PickLen {
+		DrawText(r, T("NEXT"), x, top+PanelNextY)
+		for i, s := range g.Pick[g.PickLen:] {
+			renderStoneAt(r, s.Color, x+i*StoneWidth, top+PanelNextY+16)
+		}
+	}
+}
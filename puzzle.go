@@ -0,0 +1,121 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+type GoalKind string
+
+const (
+	// erase every jammer on the board
+	ClearJammers GoalKind = "jammers"
+	// erase N times in a row by one cut
+	MakeChain GoalKind = "chain"
+	// erase every stone on the board
+	ClearBoard GoalKind = "clear"
+)
+
+type Goal struct {
+	Kind GoalKind
+	N    int
+}
+
+// Reached reports whether the goal is met after a turn
+// erasing chain times, leaving jammers and stones on the board.
+func (gl Goal) Reached(chain, jammers, stones int) bool {
+	switch gl.Kind {
+	case ClearJammers:
+		return jammers == 0
+	case MakeChain:
+		return chain >= gl.N
+	case ClearBoard:
+		return stones == 0
+	}
+	return false
+}
+
+func (gl Goal) String() string {
+	switch gl.Kind {
+	case ClearJammers:
+		return T("CLEAR JAMMERS")
+	case MakeChain:
+		return Tf("%d-CHAIN", gl.N)
+	case ClearBoard:
+		return T("CLEAR ALL")
+	}
+	return string(gl.Kind)
+}
+
+// PuzzleMode plays a stage until its goal is reached.
+type PuzzleMode struct {
+	Stage *Stage
+	next  int
+}
+
+func NewPuzzleMode(s *Stage) *PuzzleMode {
+	return &PuzzleMode{Stage: s}
+}
+
+func (m *PuzzleMode) Start(g *Game) {
+	m.next = 0
+	m.Stage.Put(g.Board)
+	g.Back = func(g *Game) { g.OpenMenu(PuzzleMenu()) }
+}
+
+func (m *PuzzleMode) Next(g *Game) *Stone {
+	if m.next < len(m.Stage.Pick) {
+		c := m.Stage.Pick[m.next]
+		m.next++
+		return &Stone{Color: c}
+	}
+	if len(m.Stage.Palette) == 0 {
+		return nil
+	}
+	if len(g.Buffer) == 0 {
+		g.Buffer = append([]Color{}, m.Stage.Palette...)
+		g.Rand.Shuffle(len(g.Buffer), func(i, j int) {
+			g.Buffer[i], g.Buffer[j] = g.Buffer[j], g.Buffer[i]
+		})
+	}
+	var c Color
+	c, g.Buffer = g.Buffer[0], g.Buffer[1:]
+	return &Stone{Color: c}
+}
+
+func (m *PuzzleMode) TurnEnd(g *Game) Step {
+	g.ReservePick()
+	if m.Stage.Goal.Reached(g.SequentErase, g.Board.Count(Jammer), g.Board.Remaining()) {
+		return Clear
+	}
+	if m.CutsLeft(g) == 0 || !g.HasLegalMove() {
+		return GameOver
+	}
+	return Move
+}
+
+// Expired gives up the puzzle when quit, a stage without a goal or cut limit ends no other way.
+func (m *PuzzleMode) Expired(g *Game) (Step, bool) {
+	return GameOver, QuitPressed(g)
+}
+
+// CutsLeft returns -1 when cuts are not limited.
+func (m *PuzzleMode) CutsLeft(g *Game) int {
+	if m.Stage.Cuts == 0 {
+		return -1
+	}
+	return maxInt(m.Stage.Cuts-g.Turn, 0)
+}
+
+func (m *PuzzleMode) Draw(g *Game, r *ebiten.Image) {
+	hud := m.Stage.Goal.String()
+	if n := m.CutsLeft(g); n >= 0 {
+		hud += "\n" + Tf("CUTS %d", n)
+	}
+	// the quit button is on the free side
+	if Setting.LeftHanded {
+		DrawTextAligned(r, hud, Viewport.Left()+ScreenWidth-2, Viewport.Top(), AlignRight)
+	} else {
+		DrawText(r, hud, Viewport.Left()+2, Viewport.Top())
+	}
+	DrawQuit(g, r)
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"math/bits"
+	"testing"
+)
+
+func bitBoardCount(bb *BitBoard, colors ...Color) int {
+	num := 0
+	for _, c := range colors {
+		for x := 0; x < BoardWidth; x++ {
+			num += bits.OnesCount16(bb.Mask[c][x])
+		}
+	}
+	return num
+}
+
+// solvePuzzle searches cuts reaching the goal on a BitBoard.
+func solvePuzzle(p *Stage, bb BitBoard, next, turn int) bool {
+	pick := p.Pick[next:]
+	if len(pick) > ReserveNum {
+		pick = pick[:ReserveNum]
+	}
+	for x := 1; x < BoardWidth-1; x++ {
+		for n := 1; n <= len(pick); n++ {
+			nb := bb
+			if !nb.Drop(x, pick[:n]) {
+				break
+			}
+			chain, _ := nb.Settle()
+			jammers := bitBoardCount(&nb, Jammer)
+			stones := bitBoardCount(&nb, Red, Blue, Green, Yellow, Pink, Orange, Jammer)
+			if p.Goal.Reached(chain, jammers, stones) {
+				return true
+			}
+			if next+n < len(p.Pick) && (p.Cuts == 0 || turn+1 < p.Cuts) {
+				if solvePuzzle(p, nb, next+n, turn+1) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func TestPuzzlesSolvable(t *testing.T) {
+	if len(Stages) == 0 {
+		t.Error("no stage")
+	}
+	for _, p := range Stages {
+		if p.Goal.Kind == "" {
+			continue
+		}
+		g := NewGame()
+		g.Mode = NewPuzzleMode(p)
+		g.Reset()
+		bb := NewBitBoard(g.Board)
+		if bb.Erase(bb.Match()) > 0 {
+			t.Error(p.Name, "matches before the first cut")
+		}
+		if !solvePuzzle(p, NewBitBoard(g.Board), 0, 0) {
+			t.Error(p.Name, "not solvable")
+		}
+	}
+}
+
+func TestPuzzleMode(t *testing.T) {
+	defer func(s Storage) { DefaultStorage = s }(DefaultStorage)
+	DefaultStorage = MemoryStorage{}
+
+	p := &Stage{
+		Name: "test",
+		Cells: []StageCell{
+			{1, 14, Red},
+			{2, 14, Red},
+		},
+		Pick: []Color{Red, Blue},
+		Cuts: 2,
+		Goal: Goal{Kind: ClearBoard},
+	}
+	type Case struct {
+		t    string
+		cuts []Point
+		step Step
+	}
+	cases := []Case{
+		Case{"clear", []Point{{3, 1}}, Clear},
+		Case{"cuts over", []Point{{4, 1}, {5, 1}}, GameOver},
+		Case{"pick over", []Point{{4, 2}}, GameOver},
+	}
+	for _, cs := range cases {
+		g := NewGame()
+		g.Mode = NewPuzzleMode(p)
+		g.Reset()
+		g.Step = Move
+		for _, c := range cs.cuts {
+			if g.Step != Move {
+				t.Error(cs.t, "not movable", g.Step)
+				break
+			}
+			g.AdjustPick(c.x, g.PickY-c.y+1)
+			g.FixPick()
+			for i := 0; i < 100 && g.Step != Move && g.Step != Clear && g.Step != GameOver; i++ {
+				g.Update()
+			}
+		}
+		if g.Step != cs.step {
+			t.Error(cs.t, g.Step, cs.step)
+		}
+		if u := LoadUnlocked(); len(u) > 0 {
+			t.Error(cs.t, "unlocked", u)
+		}
+		if s := LoadLifetime(); s.Games > 0 {
+			t.Error(cs.t, "counted in stats")
+		}
+		g.Back(g)
+		if g.Step != Select {
+			t.Error(cs.t, "puzzles not opened", g.Step)
+		}
+		g.Initialize()
+		if g.Board.Remaining() != 0 || len(g.Pick) != ReserveNum {
+			t.Error(cs.t, "stage left on the title")
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+)
+
+const RecordMax = 10
+
+// Record is the result of one finished game.
+type Record struct {
+	Score  int `json:"score"`
+	Turns  int `json:"turns"`
+	Frames int `json:"frames"`
+}
+
+// Ranked modes keep their own leaderboard.
+type Ranked interface {
+	Name() string
+	// Record returns the result of a finished game, false when it does not rank.
+	Record(g *Game) (Record, bool)
+	Better(a, b Record) bool
+	Format(r Record) string
+}
+
+func RecordKey(m Ranked) string {
+	return "records/" + strings.Replace(strings.ToLower(m.Name()), " ", "_", -1)
+}
+
+func LoadRecords(m Ranked) []Record {
+	var records []Record
+	if err := LoadData(RecordKey(m), &records); err != nil {
+		log.Println(err)
+	}
+	return records
+}
+
+// AddRecord inserts r into the leaderboard of m and returns its rank from 0,
+// or -1 when it did not get in.
+func AddRecord(m Ranked, r Record) int {
+	records := LoadRecords(m)
+	// after the records it ties with, the older ones keep their rank
+	rank := sort.Search(len(records), func(i int) bool {
+		return m.Better(r, records[i])
+	})
+	if rank >= RecordMax {
+		return -1
+	}
+	records = append(records, Record{})
+	copy(records[rank+1:], records[rank:])
+	records[rank] = r
+	if len(records) > RecordMax {
+		records = records[:RecordMax]
+	}
+	if err := SaveData(RecordKey(m), records); err != nil {
+		log.Println(err)
+	}
+	return rank
+}
+
+// RecordHandler ranks a finished game of a Ranked mode.
+func RecordHandler(g *Game, e Event) {
+	if _, ok := e.(GameEnded); !ok {
+		return
+	}
+	if m, ok := g.Mode.(Ranked); ok {
+		if r, ok := m.Record(g); ok {
+			g.Rank = AddRecord(m, r)
+		}
+	}
+}
+
+func FormatSeconds(frames int) string {
+	return fmt.Sprintf("%d.%ds", frames/TPS, frames%TPS*10/TPS)
+}
+
+func RecordsMenu() *Menu {
+	m := &Menu{Title: T("Records")}
+	for _, mode := range RankedModes() {
+		mode := mode
+		m.Items = append(m.Items, MenuItem{T(mode.Name()), func(g *Game) { g.OpenMenu(LeaderboardMenu(mode)) }})
+	}
+	m.Items = append(m.Items, MenuItem{T("Back"), func(g *Game) { g.OpenMenu(MainMenu()) }})
+	return m
+}
+
+func LeaderboardMenu(mode Ranked) *Menu {
+	m := &Menu{Title: T(mode.Name())}
+	for i, r := range LoadRecords(mode) {
+		m.Items = append(m.Items, MenuItem{fmt.Sprintf("%2d. %s", i+1, mode.Format(r)), nil})
+	}
+	if len(m.Items) == 0 {
+		m.Items = append(m.Items, MenuItem{T("no record"), nil})
+	}
+	m.Items = append(m.Items, MenuItem{T("Back"), func(g *Game) { g.OpenMenu(RecordsMenu()) }})
+	return m
+}
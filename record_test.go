@@ -0,0 +1,51 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestAddRecord(t *testing.T) {
+	defer func(s Storage) { DefaultStorage = s }(DefaultStorage)
+	DefaultStorage = MemoryStorage{}
+
+	type Case struct {
+		t     string
+		mode  Ranked
+		add   []int
+		rank  int
+		first int
+	}
+	cases := []Case{
+		Case{"first", Endless{}, []int{10}, 0, 10},
+		Case{"higher score", Endless{}, []int{10, 20}, 0, 20},
+		Case{"lower score", Endless{}, []int{20, 10}, 1, 20},
+		Case{"out of board", Endless{}, []int{5, 5, 5, 5, 5, 5, 5, 5, 5, 5, 1}, -1, 5},
+		Case{"tie", Endless{}, []int{20, 10, 10}, 2, 20},
+		Case{"tie first", Endless{}, []int{10, 10}, 1, 10},
+		Case{"tie out of board", Endless{}, []int{5, 5, 5, 5, 5, 5, 5, 5, 5, 5, 5}, -1, 5},
+		Case{"fewer frames", ClearAttack{Stones: 10}, []int{100, 50}, 0, 50},
+	}
+	for _, cs := range cases {
+		DefaultStorage = MemoryStorage{}
+		rank := -1
+		for _, n := range cs.add {
+			rank = AddRecord(cs.mode, Record{Score: n, Frames: n})
+		}
+		if rank != cs.rank {
+			t.Error(cs.t, "rank", rank, cs.rank)
+		}
+		records := LoadRecords(cs.mode)
+		if len(records) > RecordMax {
+			t.Error(cs.t, "records", len(records))
+		}
+		if len(records) == 0 || records[0].Score != cs.first {
+			t.Error(cs.t, "first", records, cs.first)
+		}
+	}
+}
+
+func TestFormatSeconds(t *testing.T) {
+	if s := FormatSeconds(TPS*61 + TPS/2); s != "61.5s" {
+		t.Error(s)
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Erasure counts what one MarkErase step has marked, see MatchResult.Erasure.
+type Erasure struct {
+	Stones    int
+	Groups    int
+	Diagonals int
+	Jammers   int
+}
+
+// ScoreInput is everything a Scorer knows about one erase step.
+type ScoreInput struct {
+	Erasure
+	Chain    int
+	AllClear bool
+}
+
+// Scorer turns an erase step into points and the equation shown on the HUD.
+type Scorer interface {
+	Score(in ScoreInput) (int, string)
+}
+
+// ScoreHandler adds the score of every erase step.
+func ScoreHandler(g *Game, e Event) {
+	if e, ok := e.(StonesMatched); ok {
+		score, equation := g.Scorer.Score(ScoreInput{
+			Erasure:  e.Match.Erasure(),
+			Chain:    e.Chain,
+			AllClear: e.AllClear,
+		})
+		g.Score += score
+		// the title shows the best of the Endless leaderboard
+		if _, ok := g.Mode.(Endless); ok {
+			g.HighScore = maxInt(g.HighScore, g.Score)
+		}
+		g.ScoreEquation = equation
+		if score > g.Stats.BestStep {
+			g.Stats.BestStep = score
+			g.Stats.BestEquation = equation
+		}
+	}
+}
+
+// ClassicScorer is the LD44 rule: 2^chain x stones.
+type ClassicScorer struct{}
+
+func (ClassicScorer) Score(in ScoreInput) (int, string) {
+	return CalcScore(in.Chain, in.Stones)
+}
+
+// ChainScorer credits each part of an erase step separately.
+// Stones are multiplied by 2^chain and by the number of groups,
+// the rest are added as bonus terms.
+type ChainScorer struct {
+	DiagonalBonus int
+	JammerBonus   int
+	AllClearBonus int
+}
+
+var DefaultScorer Scorer = ChainScorer{
+	DiagonalBonus: 5,
+	JammerBonus:   3,
+	AllClearBonus: 100,
+}
+
+func (s ChainScorer) Score(in ScoreInput) (int, string) {
+	a := 1 << uint(in.Chain)
+	b := in.Stones
+	score := a * b
+	terms := []string{fmt.Sprintf("%dx%d", a, b)}
+	if in.Groups > 1 {
+		score *= in.Groups
+		terms[0] += fmt.Sprintf("x%d", in.Groups)
+	}
+	if in.Diagonals > 0 && s.DiagonalBonus > 0 {
+		score += in.Diagonals * s.DiagonalBonus
+		terms = append(terms, fmt.Sprintf("%dx%d", in.Diagonals, s.DiagonalBonus))
+	}
+	if in.Jammers > 0 && s.JammerBonus > 0 {
+		score += in.Jammers * s.JammerBonus
+		terms = append(terms, fmt.Sprintf("%dx%d", in.Jammers, s.JammerBonus))
+	}
+	if in.AllClear && s.AllClearBonus > 0 {
+		score += s.AllClearBonus
+		terms = append(terms, fmt.Sprintf("%d", s.AllClearBonus))
+	}
+	return score, fmt.Sprintf("%s=%d.", strings.Join(terms, "+"), score)
+}
+
+// SplitEquation breaks an equation into lines of at most n glyphs,
+// cutting only before '+' or '=' when it can.
+func SplitEquation(equation string, n int) []string {
+	var lines []string
+	for len(equation) > n && n > 0 {
+		cut := strings.LastIndexAny(equation[:n+1], "+=")
+		if cut <= 0 {
+			cut = n
+		}
+		lines = append(lines, equation[:cut])
+		equation = equation[cut:]
+	}
+	return append(lines, equation)
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestChainScorer(t *testing.T) {
+	type Case struct {
+		t   string
+		in  ScoreInput
+		ans int
+		equ string
+	}
+	s := ChainScorer{DiagonalBonus: 5, JammerBonus: 3, AllClearBonus: 100}
+	cases := []Case{
+		Case{
+			"single",
+			ScoreInput{Erasure: Erasure{Stones: 3, Groups: 1}, Chain: 1},
+			6,
+			"2x3=6.",
+		},
+		Case{
+			"groups",
+			ScoreInput{Erasure: Erasure{Stones: 6, Groups: 2}, Chain: 2},
+			48,
+			"4x6x2=48.",
+		},
+		Case{
+			"diagonal jammer",
+			ScoreInput{Erasure: Erasure{Stones: 3, Groups: 1, Diagonals: 1, Jammers: 2}, Chain: 1},
+			17,
+			"2x3+1x5+2x3=17.",
+		},
+		Case{
+			"all clear",
+			ScoreInput{Erasure: Erasure{Stones: 3, Groups: 1}, Chain: 1, AllClear: true},
+			106,
+			"2x3+100=106.",
+		},
+	}
+	for _, cs := range cases {
+		ans, equ := s.Score(cs.in)
+		if ans != cs.ans || equ != cs.equ {
+			t.Error(cs.t, ans, cs.ans, equ, cs.equ)
+		}
+	}
+}
+
+func TestSplitEquation(t *testing.T) {
+	type Case struct {
+		t     string
+		equ   string
+		n     int
+		lines []string
+	}
+	cases := []Case{
+		Case{"fit", "2x3=6.", 10, []string{"2x3=6."}},
+		Case{"plus", "2x3+1x5+2x3=17.", 8, []string{"2x3+1x5", "+2x3=17."}},
+		Case{"no cut", "1234567", 3, []string{"123", "456", "7"}},
+	}
+	for _, cs := range cases {
+		lines := SplitEquation(cs.equ, cs.n)
+		if !reflect.DeepEqual(lines, cs.lines) {
+			t.Error(cs.t, lines, cs.lines)
+		}
+	}
+}
+
+func TestBoardMarkEraseCount(t *testing.T) {
+	type C struct {
+		x, y int
+		c    Color
+	}
+	type Case struct {
+		t        string
+		c        []C
+		e        Erasure
+		allClear bool
+	}
+	cases := []Case{
+		Case{
+			"horizontal",
+			[]C{
+				C{1, 14, Red},
+				C{2, 14, Red},
+				C{3, 14, Red},
+			},
+			Erasure{Stones: 3, Groups: 1},
+			true,
+		},
+		Case{
+			"cross",
+			[]C{
+				C{1, 14, Red},
+				C{2, 14, Red},
+				C{3, 14, Red},
+				C{1, 13, Red},
+				C{1, 12, Red},
+				C{2, 13, Blue},
+			},
+			Erasure{Stones: 6, Groups: 2},
+			false,
+		},
+		Case{
+			"diagonal jammer",
+			[]C{
+				C{1, 14, Red},
+				C{2, 13, Red},
+				C{3, 12, Red},
+				C{4, 12, Jammer},
+				C{3, 14, Jammer},
+			},
+			Erasure{Stones: 3, Groups: 1, Diagonals: 1, Jammers: 1},
+			false,
+		},
+	}
+	for _, cs := range cases {
+		b := NewBoard()
+		b.Initialize()
+		for _, c := range cs.c {
+			if cell, ok := b.At(c.x, c.y); ok {
+				(*cell) = &Stone{Color: c.c}
+			}
+		}
+		e := b.MarkErase().Erasure()
+		if e != cs.e {
+			t.Error(cs.t, e, cs.e)
+		}
+		if allClear := b.Remaining() == 0; allClear != cs.allClear {
+			t.Error(cs.t, "all clear", allClear, cs.allClear)
+		}
+	}
+}
+
+func TestHighScore(t *testing.T) {
+	type Case struct {
+		t    string
+		mode Mode
+		// whether the score becomes the high score
+		high bool
+	}
+	cases := []Case{
+		Case{"endless", Endless{}, true},
+		Case{"zen", Zen{}, false},
+		Case{"attack", ScoreAttack{Turns: 30}, false},
+		Case{"daily", Daily{Date: "2026-10-19", Practice: true}, false},
+	}
+	for _, cs := range cases {
+		g := NewGame()
+		g.HighScore = 0
+		g.Start(cs.mode)
+		g.Score = 90
+		g.Emit(StonesMatched{Match: MatchResult{Groups: []MatchGroup{{Cells: []Point{{1, 14}, {2, 14}, {3, 14}}}}}, Chain: 1})
+		if high := g.HighScore == g.Score && g.Score > 90; high != cs.high {
+			t.Error(cs.t, g.HighScore, g.Score)
+		}
+	}
+}
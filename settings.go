@@ -0,0 +1,272 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	// volumes are in percent
+	DefaultVolume = 40
+	VolumeStep    = 10
+)
+
+// Settings are chosen by the player and kept between sessions.
+type Settings struct {
+	// empty follows the system locale
+	Lang      Lang `json:"lang"`
+	BGMVolume int  `json:"bgm_volume"`
+	SFXVolume int  `json:"sfx_volume"`
+	Mute      bool `json:"mute"`
+	// puts the board on the right, the buttons beside it on the left
+	LeftHanded bool `json:"left_handed"`
+	// no jitter, shake or spin
+	ReducedMotion bool `json:"reduced_motion"`
+	// draw at the resolution of high DPI displays
+	HiDPI bool `json:"hidpi"`
+	// stones marked by shapes and recolored by the named palette
+	Shapes  bool   `json:"shapes"`
+	Palette string `json:"palette"`
+	// name of the theme, Classic when empty
+	Theme    string          `json:"theme"`
+	Bindings map[Action]Keys `json:"bindings"`
+}
+
+const SettingsKey = "settings"
+
+func DefaultSettings() Settings {
+	return Settings{BGMVolume: DefaultVolume, SFXVolume: DefaultVolume}
+}
+
+var Setting Settings = DefaultSettings()
+
+func LoadSettings() Settings {
+	s := DefaultSettings()
+	if err := LoadData(SettingsKey, &s); err != nil {
+		log.Println(err)
+	}
+	return s
+}
+
+func SaveSettings(s Settings) {
+	if err := SaveData(SettingsKey, s); err != nil {
+		log.Println(err)
+	}
+}
+
+func (s Settings) MusicVolume() float64 {
+	if s.Mute {
+		return 0
+	}
+	return float64(s.BGMVolume) / 100
+}
+
+func (s Settings) SoundVolume() float64 {
+	if s.Mute {
+		return 0
+	}
+	return float64(s.SFXVolume) / 100
+}
+
+// ApplySettings makes s the current settings.
+func ApplySettings(s Settings) {
+	Setting = s
+	CurrentLang = s.Lang
+	if CurrentLang == "" {
+		CurrentLang = DetectLang()
+	}
+	if CurrentTheme != nil {
+		if err := SwitchTheme(s.ThemeName()); err != nil {
+			log.Println(err)
+		}
+		UseStones(s.Palette, s.Shapes)
+	}
+	MusicChannel.Apply()
+	SFXChannel.Apply()
+}
+
+func (s Settings) ThemeName() string {
+	if s.Theme == "" {
+		return ClassicTheme
+	}
+	return s.Theme
+}
+
+// SettingOption is a row of the settings menu, changed by d steps at a time.
+type SettingOption struct {
+	Label  func(s Settings) string
+	Change func(s *Settings, d int)
+}
+
+func OnOff(b bool) string {
+	if b {
+		return T("On")
+	}
+	return T("Off")
+}
+
+// VolumeBar draws a volume as a slider.
+func VolumeBar(v int) string {
+	n := v / VolumeStep
+	return "[" + strings.Repeat("#", n) + strings.Repeat("-", 100/VolumeStep-n) + "]"
+}
+
+func stepVolume(v, d int) int {
+	n := 100/VolumeStep + 1
+	return ((v/VolumeStep+d)%n + n) % n * VolumeStep
+}
+
+var SettingOptions []SettingOption = []SettingOption{
+	{func(s Settings) string { return Tf("Language: %s", LangNames[CurrentLang]) }, func(s *Settings, d int) {
+		s.Lang = Langs[0]
+		for i, l := range Langs {
+			if l == CurrentLang {
+				s.Lang = Langs[((i+d)%len(Langs)+len(Langs))%len(Langs)]
+			}
+		}
+	}},
+	{func(s Settings) string { return fmt.Sprintf("%s %s", T("BGM"), VolumeBar(s.BGMVolume)) }, func(s *Settings, d int) {
+		s.BGMVolume = stepVolume(s.BGMVolume, d)
+	}},
+	{func(s Settings) string { return fmt.Sprintf("%s %s", T("SFX"), VolumeBar(s.SFXVolume)) }, func(s *Settings, d int) {
+		s.SFXVolume = stepVolume(s.SFXVolume, d)
+		PlaySound(S1, 0)
+	}},
+	{func(s Settings) string { return Tf("Mute: %s", OnOff(s.Mute)) }, func(s *Settings, d int) {
+		s.Mute = !s.Mute
+	}},
+	{func(s Settings) string {
+		if s.LeftHanded {
+			return T("Hand: Left")
+		}
+		return T("Hand: Right")
+	}, func(s *Settings, d int) {
+		s.LeftHanded = !s.LeftHanded
+	}},
+}
+
+// DisplayOptions are in a menu of their own to fit the screen.
+var DisplayOptions []SettingOption = []SettingOption{
+	{func(s Settings) string { return Tf("Reduce motion: %s", OnOff(s.ReducedMotion)) }, func(s *Settings, d int) {
+		s.ReducedMotion = !s.ReducedMotion
+	}},
+	{func(s Settings) string { return Tf("High DPI: %s", OnOff(s.HiDPI)) }, func(s *Settings, d int) {
+		s.HiDPI = !s.HiDPI
+	}},
+	{func(s Settings) string { return Tf("Shapes: %s", OnOff(s.Shapes)) }, func(s *Settings, d int) {
+		s.Shapes = !s.Shapes
+	}},
+	{func(s Settings) string { return Tf("Theme: %s", s.ThemeName()) }, func(s *Settings, d int) {
+		s.Theme = stepName(ThemeNames(), s.ThemeName(), d)
+	}},
+	{func(s Settings) string { return Tf("Palette: %s", T(FindPalette(s.Palette).Name)) }, func(s *Settings, d int) {
+		s.Palette = stepName(PaletteNames(), FindPalette(s.Palette).Name, d)
+	}},
+}
+
+// stepName returns the name d steps from cur in names, going round.
+func stepName(names []string, cur string, d int) string {
+	for i, n := range names {
+		if n == cur {
+			return names[((i+d)%len(names)+len(names))%len(names)]
+		}
+	}
+	return names[0]
+}
+
+// ChangeSetting changes, applies and saves the option,
+// then shows the menu made by menu again with the cursor kept.
+func ChangeSetting(g *Game, o SettingOption, d int, menu func() *Menu) {
+	s := Setting
+	o.Change(&s, d)
+	ApplySettings(s)
+	SaveSettings(s)
+	m := menu()
+	m.Cursor, m.Keyboard = g.Menu.Cursor, g.Menu.Keyboard
+	g.Menu = m
+}
+
+// OptionsMenu lists options, followed by items.
+func OptionsMenu(title string, options []SettingOption, menu func() *Menu, items ...MenuItem) *Menu {
+	m := &Menu{Title: title}
+	for _, o := range options {
+		o := o
+		m.Items = append(m.Items, MenuItem{o.Label(Setting), func(g *Game) { ChangeSetting(g, o, 1, menu) }})
+	}
+	m.Items = append(m.Items, items...)
+	m.Adjust = func(g *Game, i, d int) {
+		if i < len(options) {
+			ChangeSetting(g, options[i], d, menu)
+		}
+	}
+	return m
+}
+
+func SettingsMenu() *Menu {
+	return OptionsMenu(T("Settings"), SettingOptions, SettingsMenu,
+		MenuItem{T("Display"), func(g *Game) { g.OpenMenu(DisplayMenu()) }},
+		MenuItem{T("Keys"), func(g *Game) { g.OpenMenu(KeysMenu()) }},
+		MenuItem{T("Back"), func(g *Game) { g.OpenMenu(MainMenu()) }},
+	)
+}
+
+func DisplayMenu() *Menu {
+	return OptionsMenu(T("Display"), DisplayOptions, DisplayMenu,
+		MenuItem{T("Back"), func(g *Game) { g.OpenMenu(SettingsMenu()) }},
+	)
+}
+
+func KeyNames(ks Keys) string {
+	var names []string
+	for _, k := range ks {
+		names = append(names, k.String())
+	}
+	return strings.Join(names, " ")
+}
+
+func KeysMenu() *Menu {
+	m := &Menu{Title: T("Keys")}
+	for _, a := range Actions {
+		a := a
+		label := fmt.Sprintf("%s: %s", T(ActionNames[a]), KeyNames(Setting.Keys(a)))
+		m.Items = append(m.Items, MenuItem{label, func(g *Game) { g.OpenMenu(BindMenu(a)) }})
+	}
+	m.Items = append(m.Items,
+		MenuItem{T("Reset keys"), func(g *Game) {
+			s := Setting
+			s.Bindings = nil
+			ApplySettings(s)
+			SaveSettings(s)
+			g.OpenMenu(KeysMenu())
+		}},
+		MenuItem{T("Back"), func(g *Game) { g.OpenMenu(SettingsMenu()) }},
+	)
+	return m
+}
+
+// BindMenu waits for a key to bind to a.
+func BindMenu(a Action) *Menu {
+	m := &Menu{Title: Tf("Press a key: %s", T(ActionNames[a]))}
+	m.Items = []MenuItem{{T("Back"), func(g *Game) { g.OpenMenu(KeysMenu()) }}}
+	m.Capture = func(g *Game, k ebiten.Key) {
+		BindKey(a, k)
+		g.OpenMenu(KeysMenu())
+	}
+	return m
+}
+
+// BindKey binds k to a alone, and saves.
+func BindKey(a Action, k ebiten.Key) {
+	s := Setting
+	bindings := map[Action]Keys{}
+	for b, ks := range s.Bindings {
+		bindings[b] = ks
+	}
+	bindings[a] = Keys{k}
+	s.Bindings = bindings
+	ApplySettings(s)
+	SaveSettings(s)
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+func TestSettingsMenu(t *testing.T) {
+	defer func(s Storage) { DefaultStorage = s }(DefaultStorage)
+	DefaultStorage = MemoryStorage{}
+	defer ApplySettings(Setting)
+	g := NewGame()
+	ApplySettings(Settings{Lang: English})
+	g.OpenMenu(SettingsMenu())
+	g.Menu.Items[0].Action(g)
+	if CurrentLang != Japanese {
+		t.Error("lang", CurrentLang)
+	}
+	if s := LoadSettings(); s.Lang != Japanese {
+		t.Error("saved", s.Lang)
+	}
+	if g.Menu.Title != "設定" {
+		t.Error("title", g.Menu.Title)
+	}
+	g.Menu.Items[0].Action(g)
+	if CurrentLang != English {
+		t.Error("cycled", CurrentLang)
+	}
+}
+
+func TestSettingsVolume(t *testing.T) {
+	type Case struct {
+		t     string
+		v     int
+		d     int
+		after int
+	}
+	cases := []Case{
+		Case{"up", 40, 1, 50},
+		Case{"down", 40, -1, 30},
+		Case{"wrap up", 100, 1, 0},
+		Case{"wrap down", 0, -1, 100},
+	}
+	for _, cs := range cases {
+		if v := stepVolume(cs.v, cs.d); v != cs.after {
+			t.Error(cs.t, v, cs.after)
+		}
+	}
+	s := DefaultSettings()
+	if v := s.MusicVolume(); v != 0.4 {
+		t.Error("default", v)
+	}
+	if s := VolumeBar(s.SFXVolume); s != "[####------]" {
+		t.Error("bar", s)
+	}
+	s.Mute = true
+	if s.MusicVolume() != 0 || s.SoundVolume() != 0 {
+		t.Error("mute")
+	}
+}
+
+func TestSettingsKeys(t *testing.T) {
+	defer func(s Storage) { DefaultStorage = s }(DefaultStorage)
+	DefaultStorage = MemoryStorage{}
+	defer ApplySettings(Setting)
+	ApplySettings(DefaultSettings())
+	BindKey(ActCut, ebiten.KeyZ)
+	if ks := Setting.Keys(ActCut); len(ks) != 1 || ks[0] != ebiten.KeyZ {
+		t.Error("bound", ks)
+	}
+	if ks := Setting.Keys(ActLeft); len(ks) != 1 || ks[0] != ebiten.KeyLeft {
+		t.Error("default", ks)
+	}
+	data, err := json.Marshal(Setting)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s Settings
+	if err := json.Unmarshal(data, &s); err != nil {
+		t.Fatal(err)
+	}
+	if ks := s.Keys(ActCut); len(ks) != 1 || ks[0] != ebiten.KeyZ {
+		t.Error("loaded", ks, string(data))
+	}
+	if ks := LoadSettings().Keys(ActCut); len(ks) != 1 || ks[0] != ebiten.KeyZ {
+		t.Error("saved", ks)
+	}
+}
+
+func TestBoardBeside(t *testing.T) {
+	defer ApplySettings(Setting)
+	type Case struct {
+		t      string
+		left   bool
+		x      int
+		beside bool
+	}
+	cases := []Case{
+		Case{"right hand free side", false, ScreenWidth - 1, true},
+		Case{"right hand board", false, 40, false},
+		Case{"left hand free side", true, 0, true},
+		Case{"left hand board", true, ScreenWidth - 40, false},
+	}
+	for _, cs := range cases {
+		s := Setting
+		s.LeftHanded = cs.left
+		ApplySettings(s)
+		b := NewBoard()
+		b.Initialize()
+		if beside := b.Beside(cs.x); beside != cs.beside {
+			t.Error(cs.t, beside)
+		}
+		if cx, _ := b.PosToCell(b.OriginX+StoneWidth*3, 0); cx != 3 {
+			t.Error(cs.t, "cell", cx)
+		}
+	}
+}
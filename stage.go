@@ -0,0 +1,403 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A stage is written as text, one setting per line:
+//
+//	# comment
+//	name Diagonal
+//	palette RGBY
+//	pick YGBB
+//	cuts 3
+//	goal clear
+//	put 3 12 J
+//	board
+//	Y.....
+//	GYB#..
+//
+// Rows after "board" are the playfield without the side walls,
+// the last row lying on the floor. "put x y c" places one cell
+// in board coordinates. Cells are written with ColorLetters.
+
+var ColorLetters map[Color]byte = map[Color]byte{
+	None:   '.',
+	Red:    'R',
+	Blue:   'B',
+	Green:  'G',
+	Yellow: 'Y',
+	Pink:   'P',
+	Orange: 'O',
+	Wall:   '#',
+	Jammer: 'J',
+}
+
+func ParseColorLetter(l byte) (Color, bool) {
+	for c, cl := range ColorLetters {
+		if cl == l {
+			return c, true
+		}
+	}
+	return None, false
+}
+
+type StageCell struct {
+	X, Y  int
+	Color Color
+}
+
+// Stage is an authored starting board.
+// Pick is dealt first, then stones come from Palette at random.
+// Goal is empty for a stage played without a goal,
+// Cuts limits the number of cuts, 0 means no limit.
+type Stage struct {
+	Name    string
+	Palette []Color
+	Pick    []Color
+	Cuts    int
+	Goal    Goal
+	Cells   []StageCell
+}
+
+var Stages []*Stage
+
+type StageError struct {
+	Line int
+	Msg  string
+}
+
+func (e StageError) Error() string {
+	if e.Line == 0 {
+		return e.Msg
+	}
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+// StageErrors are all problems found in one stage.
+type StageErrors []StageError
+
+func (es StageErrors) Error() string {
+	var msgs []string
+	for _, e := range es {
+		msgs = append(msgs, e.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func InPlayfield(x, y int) bool {
+	return 1 <= x && x < BoardWidth-1 && 1 <= y && y < BoardHeight-1
+}
+
+func parseLetters(s string) ([]Color, error) {
+	var colors []Color
+	for i := 0; i < len(s); i++ {
+		c, ok := ParseColorLetter(s[i])
+		if !ok {
+			return nil, fmt.Errorf("unknown color %q", s[i])
+		}
+		colors = append(colors, c)
+	}
+	return colors, nil
+}
+
+func ParseGoal(s string) (Goal, error) {
+	f := strings.Fields(s)
+	if len(f) == 0 {
+		return Goal{}, fmt.Errorf("empty goal")
+	}
+	switch GoalKind(f[0]) {
+	case ClearJammers, ClearBoard:
+		if len(f) != 1 {
+			return Goal{}, fmt.Errorf("goal %s takes no number", f[0])
+		}
+		return Goal{Kind: GoalKind(f[0])}, nil
+	case MakeChain:
+		if len(f) != 2 {
+			return Goal{}, fmt.Errorf("goal chain needs a number")
+		}
+		n, err := strconv.Atoi(f[1])
+		if err != nil {
+			return Goal{}, err
+		}
+		return Goal{Kind: MakeChain, N: n}, nil
+	}
+	return Goal{}, fmt.Errorf("unknown goal %q", f[0])
+}
+
+func ParseStage(r io.Reader) (*Stage, error) {
+	s := &Stage{}
+	var errs StageErrors
+	fail := func(line int, format string, a ...interface{}) {
+		errs = append(errs, StageError{line, fmt.Sprintf(format, a...)})
+	}
+	type row struct {
+		line int
+		text string
+	}
+	var rows []row
+	inBoard := false
+
+	sc := bufio.NewScanner(r)
+	line := 0
+	for sc.Scan() {
+		line++
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, "#") && !inBoard {
+			continue
+		}
+		if inBoard {
+			rows = append(rows, row{line, text})
+			continue
+		}
+		key, value := text, ""
+		if i := strings.IndexByte(text, ' '); i >= 0 {
+			key, value = text[:i], strings.TrimSpace(text[i+1:])
+		}
+		switch key {
+		case "name":
+			s.Name = value
+		case "palette", "pick":
+			colors, err := parseLetters(value)
+			if err != nil {
+				fail(line, "%s: %v", key, err)
+			} else if key == "palette" {
+				s.Palette = colors
+			} else {
+				s.Pick = colors
+			}
+		case "cuts":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				fail(line, "bad cuts %q", value)
+			}
+			s.Cuts = n
+		case "goal":
+			goal, err := ParseGoal(value)
+			if err != nil {
+				fail(line, "%v", err)
+			}
+			s.Goal = goal
+		case "put":
+			f := strings.Fields(value)
+			if len(f) != 3 || len(f[2]) != 1 {
+				fail(line, "put needs x y color")
+				continue
+			}
+			x, errx := strconv.Atoi(f[0])
+			y, erry := strconv.Atoi(f[1])
+			c, ok := ParseColorLetter(f[2][0])
+			if errx != nil || erry != nil {
+				fail(line, "bad coordinate %s %s", f[0], f[1])
+			} else if !InPlayfield(x, y) {
+				fail(line, "coordinate %d %d out of the playfield", x, y)
+			} else if !ok {
+				fail(line, "unknown color %q", f[2][0])
+			} else {
+				s.Cells = append(s.Cells, StageCell{x, y, c})
+			}
+		case "board":
+			inBoard = true
+		default:
+			fail(line, "unknown setting %q", key)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(rows) > BoardHeight-2 {
+		fail(rows[0].line, "board has %d rows, at most %d", len(rows), BoardHeight-2)
+	}
+	for i, rw := range rows {
+		y := BoardHeight - 1 - len(rows) + i
+		if len(rw.text) != BoardWidth-2 {
+			fail(rw.line, "row is %d cells wide, want %d", len(rw.text), BoardWidth-2)
+			continue
+		}
+		for j := 0; j < len(rw.text); j++ {
+			c, ok := ParseColorLetter(rw.text[j])
+			if !ok {
+				fail(rw.line, "unknown color %q", rw.text[j])
+			} else if c != None && y >= 1 {
+				s.Cells = append(s.Cells, StageCell{j + 1, y, c})
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Validate checks what the parser can not see line by line.
+func (s *Stage) Validate() error {
+	var errs StageErrors
+	fail := func(format string, a ...interface{}) {
+		errs = append(errs, StageError{0, fmt.Sprintf(format, a...)})
+	}
+	inPalette := func(c Color) bool {
+		if len(s.Palette) == 0 {
+			return true
+		}
+		for _, p := range s.Palette {
+			if p == c {
+				return true
+			}
+		}
+		return false
+	}
+	for _, c := range s.Palette {
+		if !c.Colored() {
+			fail("palette: %s is not a stone color", c)
+		}
+	}
+	for i, c := range s.Pick {
+		if !c.Colored() {
+			fail("pick %d: %s is not a stone color", i+1, c)
+		} else if !inPalette(c) {
+			fail("pick %d: %s is not in the palette", i+1, c)
+		}
+	}
+	if len(s.Pick) == 0 && len(s.Palette) == 0 {
+		fail("no pick and no palette")
+	}
+	seen := map[Point]bool{}
+	for _, cell := range s.Cells {
+		p := Point{cell.X, cell.Y}
+		if !InPlayfield(cell.X, cell.Y) {
+			fail("cell %d %d: out of the playfield", cell.X, cell.Y)
+		}
+		if seen[p] {
+			fail("cell %d %d: placed twice", cell.X, cell.Y)
+		}
+		seen[p] = true
+		if !cell.Color.Colored() && cell.Color != Jammer && cell.Color != Wall {
+			fail("cell %d %d: %s is not a stone, jammer or wall", cell.X, cell.Y, cell.Color)
+		} else if cell.Color.Colored() && !inPalette(cell.Color) {
+			fail("cell %d %d: %s is not in the palette", cell.X, cell.Y, cell.Color)
+		}
+	}
+	switch s.Goal.Kind {
+	case "", ClearBoard:
+	case ClearJammers:
+		jammers := 0
+		for _, cell := range s.Cells {
+			if cell.Color == Jammer {
+				jammers++
+			}
+		}
+		if jammers == 0 {
+			fail("goal jammers: no jammer on the board")
+		}
+	case MakeChain:
+		if s.Goal.N < 1 {
+			fail("goal chain: %d is too short", s.Goal.N)
+		}
+	default:
+		fail("unknown goal %q", s.Goal.Kind)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Board builds the starting board of the stage.
+func (s *Stage) Board() *Board {
+	b := NewBoard()
+	b.Initialize()
+	s.Put(b)
+	return b
+}
+
+func (s *Stage) Put(b *Board) {
+	for _, cell := range s.Cells {
+		if c, ok := b.At(cell.X, cell.Y); ok {
+			*c = &Stone{Color: cell.Color}
+		}
+	}
+}
+
+func formatLetters(colors []Color) string {
+	var sb strings.Builder
+	for _, c := range colors {
+		sb.WriteByte(ColorLetters[c])
+	}
+	return sb.String()
+}
+
+// Format writes the stage back as text, the board always as rows.
+func (s *Stage) Format() string {
+	var sb strings.Builder
+	if s.Name != "" {
+		fmt.Fprintf(&sb, "name %s\n", s.Name)
+	}
+	if len(s.Palette) > 0 {
+		fmt.Fprintf(&sb, "palette %s\n", formatLetters(s.Palette))
+	}
+	if len(s.Pick) > 0 {
+		fmt.Fprintf(&sb, "pick %s\n", formatLetters(s.Pick))
+	}
+	if s.Cuts > 0 {
+		fmt.Fprintf(&sb, "cuts %d\n", s.Cuts)
+	}
+	switch s.Goal.Kind {
+	case "":
+	case MakeChain:
+		fmt.Fprintf(&sb, "goal %s %d\n", s.Goal.Kind, s.Goal.N)
+	default:
+		fmt.Fprintf(&sb, "goal %s\n", s.Goal.Kind)
+	}
+	sb.WriteString("board\n")
+	b := s.Board()
+	top := BoardHeight - 2
+	for _, cell := range s.Cells {
+		top = minInt(top, cell.Y)
+	}
+	for y := top; y < BoardHeight-1; y++ {
+		for x := 1; x < BoardWidth-1; x++ {
+			sb.WriteByte(ColorLetters[b.ColorAt(x, y)])
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// LoadStages reads every .txt file in dir, sorted by name.
+func LoadStages(fsys fs.FS, dir string) ([]*Stage, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	var stages []*Stage
+	for _, e := range entries {
+		if e.IsDir() || path.Ext(e.Name()) != ".txt" {
+			continue
+		}
+		f, err := fsys.Open(path.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		s, err := ParseStage(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", e.Name(), err)
+		}
+		stages = append(stages, s)
+	}
+	return stages, nil
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestStageGolden(t *testing.T) {
+	files, err := filepath.Glob("testdata/stage/*.txt")
+	if err != nil || len(files) == 0 {
+		t.Fatal("no stage", err)
+	}
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got string
+		if s, err := ParseStage(f); err != nil {
+			got = "error\n" + err.Error() + "\n"
+		} else {
+			got = s.Format()
+		}
+		f.Close()
+
+		golden := strings.TrimSuffix(file, ".txt") + ".golden"
+		if *update {
+			if err := ioutil.WriteFile(golden, []byte(got), 0644); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		want, err := ioutil.ReadFile(golden)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != string(want) {
+			t.Errorf("%s\ngot:\n%s\nwant:\n%s", file, got, want)
+		}
+	}
+}
+
+func TestStageFormatRoundTrip(t *testing.T) {
+	for _, s := range Stages {
+		s2, err := ParseStage(strings.NewReader(s.Format()))
+		if err != nil {
+			t.Error(s.Name, err)
+			continue
+		}
+		if s2.Format() != s.Format() {
+			t.Error(s.Name, s2.Format(), s.Format())
+		}
+	}
+}
+
+func TestStageValidateCells(t *testing.T) {
+	type Case struct {
+		t     string
+		color Color
+		valid bool
+	}
+	cases := []Case{
+		Case{"stone", Red, true},
+		Case{"jammer", Jammer, true},
+		Case{"wall", Wall, true},
+		Case{"none", None, false},
+		Case{"limit", Limit, false},
+		Case{"cursor", Cursor, false},
+	}
+	for _, cs := range cases {
+		s := &Stage{Name: cs.t, Pick: []Color{Red}, Cells: []StageCell{{1, 14, cs.color}}}
+		if err := s.Validate(); (err == nil) != cs.valid {
+			t.Error(cs.t, err)
+		}
+	}
+}
+
+func TestStageBoard(t *testing.T) {
+	s, err := ParseStage(strings.NewReader("pick R\nput 3 3 J\nboard\n#.....\nRG....\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	type C struct {
+		x, y int
+		c    Color
+	}
+	b := s.Board()
+	for _, c := range []C{
+		C{3, 3, Jammer},
+		C{1, 13, Wall},
+		C{1, 14, Red},
+		C{2, 14, Green},
+		C{2, 13, None},
+		C{0, 14, Wall},
+	} {
+		if got := b.ColorAt(c.x, c.y); got != c.c {
+			t.Error(c.x, c.y, got, c.c)
+		}
+	}
+	// walls in the playfield do not fall
+	for b.FallStone() {
+	}
+	if got := b.ColorAt(1, 13); got != Wall {
+		t.Error("wall fell", got)
+	}
+	if got := b.ColorAt(3, 14); got != Jammer {
+		t.Error("jammer did not fall", got)
+	}
+}
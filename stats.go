@@ -0,0 +1,193 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Stats are counted for each game and summed up for the lifetime.
+type Stats struct {
+	Games          int           `json:"games"`
+	Cuts           int           `json:"cuts"`
+	Placed         int           `json:"placed"`
+	Cleared        map[Color]int `json:"cleared"`
+	JammersDropped int           `json:"jammers_dropped"`
+	JammersCleared int           `json:"jammers_cleared"`
+	MaxChain       int           `json:"max_chain"`
+	BestStep       int           `json:"best_step"`
+	BestEquation   string        `json:"best_equation"`
+	// turns played at each number of colors
+	LevelTurns map[int]int `json:"level_turns"`
+}
+
+// Leveled modes deal stones of Level colors.
+type Leveled interface {
+	Level(g *Game) int
+}
+
+func NewStats() Stats {
+	return Stats{Cleared: map[Color]int{}, LevelTurns: map[int]int{}}
+}
+
+// AveragePick is the average number of stones in a cut.
+func (s Stats) AveragePick() float64 {
+	if s.Cuts == 0 {
+		return 0
+	}
+	return float64(s.Placed) / float64(s.Cuts)
+}
+
+// TotalCleared counts erased colored stones.
+func (s Stats) TotalCleared() int {
+	num := 0
+	for _, n := range s.Cleared {
+		num += n
+	}
+	return num
+}
+
+// Add sums o into s, keeping the best of chains and steps.
+func (s *Stats) Add(o Stats) {
+	if s.Cleared == nil {
+		s.Cleared = map[Color]int{}
+	}
+	if s.LevelTurns == nil {
+		s.LevelTurns = map[int]int{}
+	}
+	s.Games += o.Games
+	s.Cuts += o.Cuts
+	s.Placed += o.Placed
+	for c, n := range o.Cleared {
+		s.Cleared[c] += n
+	}
+	s.JammersDropped += o.JammersDropped
+	s.JammersCleared += o.JammersCleared
+	s.MaxChain = maxInt(s.MaxChain, o.MaxChain)
+	if o.BestStep > s.BestStep {
+		s.BestStep = o.BestStep
+		s.BestEquation = o.BestEquation
+	}
+	for l, n := range o.LevelTurns {
+		s.LevelTurns[l] += n
+	}
+}
+
+// Lines formats the stats to fit the board width.
+func (s Stats) Lines() []string {
+	var cleared []string
+	for _, c := range []Color{Red, Blue, Green, Yellow, Pink, Orange} {
+		cleared = append(cleared, fmt.Sprintf("%c%d", ColorLetters[c], s.Cleared[c]))
+	}
+	var levels []string
+	for l := 1; l <= 6; l++ {
+		if n := s.LevelTurns[l]; n > 0 {
+			levels = append(levels, fmt.Sprintf("%d:%d", l, n))
+		}
+	}
+	return []string{
+		Tf("CUTS %d AVG %.1f", s.Cuts, s.AveragePick()),
+		Tf("PLACED %d", s.Placed),
+		strings.Join(cleared[:3], " "),
+		strings.Join(cleared[3:], " "),
+		Tf("JAMMERS +%d -%d", s.JammersDropped, s.JammersCleared),
+		Tf("MAX CHAIN %d", s.MaxChain),
+		Tf("COLORS %s", strings.Join(levels, " ")),
+		// last, the equation follows
+		Tf("BEST STEP %d", s.BestStep),
+	}
+}
+
+const (
+	StatsWidth = (BoardWidth - 1) * StoneWidth
+	// lines of the best equation in the stats box
+	StatsEquationLines = 2
+)
+
+// EquationLines splits the best equation into lines of n glyphs, none before any erase.
+func (s Stats) EquationLines(n int) []string {
+	if s.BestEquation == "" {
+		return nil
+	}
+	return SplitEquation(s.BestEquation, n)
+}
+
+// RenderStats draws the lines on a dark box, x, y is left top.
+func RenderStats(r *ebiten.Image, s Stats, x, y int) {
+	lines := s.Lines()
+	equation := s.EquationLines(StatsWidth / NumberWidth)
+	if len(equation) > StatsEquationLines {
+		equation = equation[:StatsEquationLines]
+	}
+	FillRect(r, float64(x-2), float64(y-2), float64(StatsWidth+4), float64(len(lines)*16+len(equation)*NumberHeight+4), color.RGBA{0, 0, 0, 0xa0})
+	for i, l := range lines {
+		DrawText(r, l, x, y+i*16)
+	}
+	for i, l := range equation {
+		RenderNumberText(r, l, x, y+len(lines)*16+i*NumberHeight)
+	}
+}
+
+// StatsHandler counts the game's stats and adds them to the lifetime when it ends.
+func StatsHandler(g *Game, e Event) {
+	switch e := e.(type) {
+	case CutPlaced:
+		g.Stats.Cuts++
+		g.Stats.Placed += len(e.Stones)
+	case StonesMatched:
+		seen := map[Point]bool{}
+		for _, m := range e.Match.Groups {
+			for _, p := range m.Cells {
+				if !seen[p] {
+					seen[p] = true
+					g.Stats.Cleared[m.Color]++
+				}
+			}
+		}
+		g.Stats.JammersCleared += len(e.Match.Jammers)
+	case ChainAdvanced:
+		g.Stats.MaxChain = maxInt(g.Stats.MaxChain, e.Chain)
+	case JammerDropped:
+		g.Stats.JammersDropped += len(e.Points)
+	case TurnEnded:
+		if m, ok := g.Mode.(Leveled); ok {
+			g.Stats.LevelTurns[m.Level(g)]++
+		}
+	case GameEnded:
+		g.Stats.Games = 1
+		if !g.Real() {
+			return
+		}
+		lifetime := LoadLifetime()
+		lifetime.Add(g.Stats)
+		if err := SaveData(LifetimeKey, lifetime); err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+const LifetimeKey = "stats"
+
+func LoadLifetime() Stats {
+	s := NewStats()
+	if err := LoadData(LifetimeKey, &s); err != nil {
+		log.Println(err)
+	}
+	return s
+}
+
+func StatsMenu() *Menu {
+	s := LoadLifetime()
+	m := &Menu{Title: Tf("Stats  %d games", s.Games)}
+	for _, l := range s.Lines() {
+		m.Items = append(m.Items, MenuItem{l, nil})
+	}
+	for _, l := range s.EquationLines((ScreenWidth - MenuX) / FontWidth) {
+		m.Items = append(m.Items, MenuItem{" " + l, nil})
+	}
+	m.Items = append(m.Items, MenuItem{T("Back"), func(g *Game) { g.OpenMenu(MainMenu()) }})
+	return m
+}
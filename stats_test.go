@@ -0,0 +1,106 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStatsHandler(t *testing.T) {
+	defer func(s Storage) { DefaultStorage = s }(DefaultStorage)
+	DefaultStorage = MemoryStorage{}
+
+	g := NewGame()
+	g.Start(Endless{})
+	events := []Event{
+		CutPlaced{3, 5, []*Stone{{Color: Red}, {Color: Red}}},
+		StonesMatched{MatchResult{
+			Groups: []MatchGroup{
+				{[]Point{{1, 14}, {2, 14}, {3, 14}}, Red, Horizontal},
+				{[]Point{{3, 12}, {3, 13}, {3, 14}}, Red, Vertical},
+			},
+			Jammers: []Point{{4, 14}},
+		}, 1, false},
+		ChainAdvanced{1},
+		StonesMatched{MatchResult{
+			Groups: []MatchGroup{{[]Point{{1, 14}, {2, 14}, {3, 14}}, Blue, Horizontal}},
+		}, 2, false},
+		ChainAdvanced{2},
+		TurnEnded{1, Move},
+		CutPlaced{1, 5, []*Stone{{Color: Blue}}},
+		JammerDropped{[]Point{{1, 3}, {2, 3}}},
+		TurnEnded{2, GameOver},
+	}
+	for _, e := range events {
+		g.Emit(e)
+	}
+	g.End(GameOver)
+
+	want := Stats{
+		Games:          1,
+		Cuts:           2,
+		Placed:         3,
+		Cleared:        map[Color]int{Red: 5, Blue: 3},
+		JammersDropped: 2,
+		JammersCleared: 1,
+		MaxChain:       2,
+		BestStep:       g.Stats.BestStep,
+		BestEquation:   g.Stats.BestEquation,
+		LevelTurns:     map[int]int{3: 2},
+	}
+	if !reflect.DeepEqual(g.Stats, want) {
+		t.Error("game", g.Stats, want)
+	}
+	if g.Stats.BestStep == 0 || g.Stats.BestStep >= g.Score {
+		t.Error("best step", g.Stats.BestStep, g.Score)
+	}
+	if a := g.Stats.AveragePick(); a != 1.5 {
+		t.Error("average", a)
+	}
+
+	g.Start(Endless{})
+	g.Emit(CutPlaced{1, 5, []*Stone{{Color: Blue}}})
+	g.End(GameOver)
+	lifetime := LoadLifetime()
+	if lifetime.Games != 2 || lifetime.Cuts != 3 || lifetime.MaxChain != 2 || lifetime.Cleared[Red] != 5 {
+		t.Error("lifetime", lifetime)
+	}
+}
+
+func TestStatsLines(t *testing.T) {
+	defer func(s Storage) { DefaultStorage = s }(DefaultStorage)
+	DefaultStorage = MemoryStorage{}
+
+	s := NewStats()
+	s.Cuts = 4
+	s.Placed = 10
+	s.Cleared[Green] = 6
+	s.LevelTurns[3] = 24
+	s.LevelTurns[4] = 2
+	want := []string{
+		"CUTS 4 AVG 2.5",
+		"PLACED 10",
+		"R0 B0 G6",
+		"Y0 P0 O0",
+		"JAMMERS +0 -0",
+		"MAX CHAIN 0",
+		"COLORS 3:24 4:2",
+		"BEST STEP 0",
+	}
+	if l := s.Lines(); !reflect.DeepEqual(l, want) {
+		t.Error(l, want)
+	}
+	if l := s.EquationLines(7); l != nil {
+		t.Error("equation without erase", l)
+	}
+	s.BestEquation = "4x3+1x5=17."
+	if l := s.EquationLines(7); !reflect.DeepEqual(l, []string{"4x3+1x5", "=17."}) {
+		t.Error("equation", l)
+	}
+	if err := SaveData(LifetimeKey, s); err != nil {
+		t.Fatal(err)
+	}
+	m := StatsMenu()
+	if item := m.Items[len(s.Lines())]; item.Label != " 4x3+1x5=17." {
+		t.Error("menu equation", item.Label)
+	}
+}
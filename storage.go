@@ -0,0 +1,43 @@
+package main
+
+import (
+	"encoding/json"
+)
+
+// Storage keeps small data like records between sessions.
+// Load returns nil without error when nothing is saved for the key.
+type Storage interface {
+	Load(key string) ([]byte, error)
+	Save(key string, data []byte) error
+}
+
+// MemoryStorage is used when there is nowhere to persist, and in tests.
+type MemoryStorage map[string][]byte
+
+func (m MemoryStorage) Load(key string) ([]byte, error) {
+	return m[key], nil
+}
+
+func (m MemoryStorage) Save(key string, data []byte) error {
+	m[key] = append([]byte{}, data...)
+	return nil
+}
+
+var DefaultStorage Storage = NewStorage()
+
+// LoadData decodes the saved value of key into v, leaving v as is when nothing is saved.
+func LoadData(key string, v interface{}) error {
+	data, err := DefaultStorage.Load(key)
+	if err != nil || data == nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func SaveData(key string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return DefaultStorage.Save(key, data)
+}
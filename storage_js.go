@@ -0,0 +1,34 @@
+//go:build js
+// +build js
+
+package main
+
+import (
+	"syscall/js"
+)
+
+// LocalStorage saves to the browser's localStorage.
+type LocalStorage struct {
+	v js.Value
+}
+
+func NewStorage() Storage {
+	v := js.Global().Get("localStorage")
+	if v.IsUndefined() || v.IsNull() {
+		return MemoryStorage{}
+	}
+	return LocalStorage{v}
+}
+
+func (s LocalStorage) Load(key string) ([]byte, error) {
+	v := s.v.Call("getItem", "cutnalign/"+key)
+	if v.IsNull() || v.IsUndefined() {
+		return nil, nil
+	}
+	return []byte(v.String()), nil
+}
+
+func (s LocalStorage) Save(key string, data []byte) error {
+	s.v.Call("setItem", "cutnalign/"+key, string(data))
+	return nil
+}
@@ -0,0 +1,43 @@
+//go:build !js
+// +build !js
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// FileStorage saves one file per key under the user config directory.
+type FileStorage struct {
+	Dir string
+}
+
+func NewStorage() Storage {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return MemoryStorage{}
+	}
+	return FileStorage{filepath.Join(dir, "cutnalign")}
+}
+
+func (s FileStorage) path(key string) string {
+	return filepath.Join(s.Dir, filepath.FromSlash(key)+".json")
+}
+
+func (s FileStorage) Load(key string) ([]byte, error) {
+	data, err := ioutil.ReadFile(s.path(key))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return data, err
+}
+
+func (s FileStorage) Save(key string, data []byte) error {
+	p := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(p, data, 0644)
+}
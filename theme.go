@@ -0,0 +1,219 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"io/fs"
+	"path"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Theme tells where each sprite lives in an atlas image.
+type Theme struct {
+	Name string `json:"name"`
+	// path of the atlas, relative to the manifest
+	Image string `json:"image"`
+	// atlas pixels per screen pixel, 2 for a double resolution atlas
+	Scale   int    `json:"scale"`
+	Stones  Strip  `json:"stones"`
+	Numbers Strip  `json:"numbers"`
+	Alpha   Strip  `json:"alpha"`
+	Letters string `json:"letters"`
+
+	Atlas image.Image `json:"-"`
+}
+
+// Strip is a grid of sprites of the same size, numbered from left to right, top to bottom.
+type Strip struct {
+	X    int `json:"x"`
+	Y    int `json:"y"`
+	W    int `json:"w"`
+	H    int `json:"h"`
+	Cols int `json:"cols"`
+	// rows of the grid in use, all when empty
+	Rows []int `json:"rows"`
+}
+
+func (s Strip) Rect(i int) image.Rectangle {
+	x, y := i%s.Cols, i/s.Cols
+	if len(s.Rows) > 0 {
+		y = s.Rows[y]
+	}
+	return image.Rect(s.X+s.W*x, s.Y+s.H*y, s.X+s.W*(x+1), s.Y+s.H*(y+1))
+}
+
+// Len is the number of sprites the strip can hold.
+func (s Strip) Len() int {
+	if len(s.Rows) == 0 {
+		return -1
+	}
+	return len(s.Rows) * s.Cols
+}
+
+const (
+	ThemeManifest   = "theme.json"
+	ClassicThemeDir = "asset/theme/classic"
+	ClassicTheme    = "Classic"
+)
+
+// validate checks that the strip holds n sprites of w x h screen pixels inside the atlas.
+func (t *Theme) validate(name string, s Strip, n, w, h int) error {
+	if s.Cols <= 0 {
+		return fmt.Errorf("theme %q: %s: cols must be positive", t.Name, name)
+	}
+	if s.W != w*t.Scale || s.H != h*t.Scale {
+		return fmt.Errorf("theme %q: %s: sprites must be %dx%d at scale %d, not %dx%d", t.Name, name, w*t.Scale, h*t.Scale, t.Scale, s.W, s.H)
+	}
+	if l := s.Len(); l >= 0 && l < n {
+		return fmt.Errorf("theme %q: %s: %d rows hold %d sprites, %d needed", t.Name, name, len(s.Rows), l, n)
+	}
+	b := t.Atlas.Bounds()
+	for i := 0; i < n; i++ {
+		if r := s.Rect(i); !r.In(b) {
+			return fmt.Errorf("theme %q: %s: sprite %d at %v is outside the %dx%d image", t.Name, name, i, r, b.Dx(), b.Dy())
+		}
+	}
+	return nil
+}
+
+func (t *Theme) Validate() error {
+	if t.Scale <= 0 {
+		return fmt.Errorf("theme %q: scale must be positive", t.Name)
+	}
+	if err := t.validate("stones", t.Stones, len(Colors), StoneWidth, StoneHeight); err != nil {
+		return err
+	}
+	if err := t.validate("numbers", t.Numbers, Plus+1, NumberWidth, NumberHeight); err != nil {
+		return err
+	}
+	return t.validate("alpha", t.Alpha, len([]rune(t.Letters)), AlphaWidth, AlphaHeight)
+}
+
+// LoadTheme reads the manifest in dir of fsys and its atlas.
+func LoadTheme(fsys fs.FS, dir string) (*Theme, error) {
+	data, err := fs.ReadFile(fsys, path.Join(dir, ThemeManifest))
+	if err != nil {
+		return nil, err
+	}
+	t := &Theme{Scale: 1}
+	if err := json.Unmarshal(data, t); err != nil {
+		return nil, fmt.Errorf("%s: %v", path.Join(dir, ThemeManifest), err)
+	}
+	if t.Name == "" {
+		t.Name = path.Base(dir)
+	}
+	f, err := fsys.Open(path.Join(dir, t.Image))
+	if err != nil {
+		return nil, fmt.Errorf("theme %q: %v", t.Name, err)
+	}
+	defer f.Close()
+	if t.Atlas, err = png.Decode(f); err != nil {
+		return nil, fmt.Errorf("theme %q: %s: %v", t.Name, t.Image, err)
+	}
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// ThemeSource is where a theme can be loaded from.
+type ThemeSource struct {
+	Name string
+	FS   fs.FS
+	Dir  string
+}
+
+// ThemeSources lists the embedded themes, then the local ones.
+func ThemeSources() []ThemeSource {
+	var sources []ThemeSource
+	add := func(fsys fs.FS, root string) {
+		dirs, err := fs.ReadDir(fsys, root)
+		if err != nil {
+			return
+		}
+		for _, d := range dirs {
+			dir := path.Join(root, d.Name())
+			var t Theme
+			if data, err := fs.ReadFile(fsys, path.Join(dir, ThemeManifest)); err != nil || json.Unmarshal(data, &t) != nil {
+				continue
+			}
+			if t.Name == "" {
+				t.Name = d.Name()
+			}
+			sources = append(sources, ThemeSource{t.Name, fsys, dir})
+		}
+	}
+	add(asset, path.Dir(ClassicThemeDir))
+	if fsys := LocalThemes(); fsys != nil {
+		add(fsys, ".")
+	}
+	return sources
+}
+
+var (
+	CurrentTheme *Theme
+	// sprites are drawn scaled by this to screen pixels
+	SpriteScale float64 = 1
+)
+
+// UseTheme cuts the sprites from the atlas of t and draws with them from now on.
+func UseTheme(t *Theme) {
+	Texture = ebiten.NewImageFromImage(t.Atlas)
+	TextureSource = t.Atlas
+	sub := func(r image.Rectangle) *ebiten.Image {
+		return Texture.SubImage(r).(*ebiten.Image)
+	}
+	stones := map[Color]*ebiten.Image{}
+	for _, c := range Colors {
+		stones[c] = sub(t.Stones.Rect(int(c)))
+	}
+	NumberImages = map[int]*ebiten.Image{}
+	for i := 0; i <= Plus; i++ {
+		NumberImages[i] = sub(t.Numbers.Rect(i))
+	}
+	AlphaImages = map[rune]*ebiten.Image{}
+	for i, c := range []rune(t.Letters) {
+		AlphaImages[c] = sub(t.Alpha.Rect(i))
+	}
+	CurrentTheme = t
+	SpriteScale = 1 / float64(t.Scale)
+	ClassicStones = stones
+	stoneSets = map[string]map[Color]*ebiten.Image{}
+	UseStones(Setting.Palette, Setting.Shapes)
+}
+
+// SwitchTheme loads the named theme, keeping the current one if it fails.
+func SwitchTheme(name string) error {
+	if CurrentTheme != nil && CurrentTheme.Name == name {
+		return nil
+	}
+	for _, s := range ThemeSources() {
+		if s.Name == name {
+			t, err := LoadTheme(s.FS, s.Dir)
+			if err != nil {
+				return err
+			}
+			UseTheme(t)
+			return nil
+		}
+	}
+	return fmt.Errorf("theme %q not found", name)
+}
+
+// SpriteOptions draws a sprite of the theme at the size of the screen pixels.
+func SpriteOptions() *ebiten.DrawImageOptions {
+	opt := &ebiten.DrawImageOptions{Filter: ebiten.FilterNearest}
+	opt.GeoM.Scale(SpriteScale, SpriteScale)
+	return opt
+}
+
+func ThemeNames() []string {
+	var names []string
+	for _, s := range ThemeSources() {
+		names = append(names, s.Name)
+	}
+	return names
+}
@@ -0,0 +1,13 @@
+//go:build js
+// +build js
+
+package main
+
+import (
+	"io/fs"
+)
+
+// LocalThemes is nil, a browser has no files to load themes from.
+func LocalThemes() fs.FS {
+	return nil
+}
@@ -0,0 +1,19 @@
+//go:build !js
+// +build !js
+
+package main
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// LocalThemes are the directories under themes in the user config directory.
+func LocalThemes() fs.FS {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return nil
+	}
+	return os.DirFS(filepath.Join(dir, "cutnalign", "themes"))
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestClassicTheme(t *testing.T) {
+	th, err := LoadTheme(asset, ClassicThemeDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	type Case struct {
+		t    string
+		r    image.Rectangle
+		want image.Rectangle
+	}
+	// the layout once hard coded in init
+	cases := []Case{
+		Case{"stone", th.Stones.Rect(int(Jammer)), image.Rect(48, 16, 64, 32)},
+		Case{"number", th.Numbers.Rect(9), image.Rect(16, 64, 32, 96)},
+		Case{"plus", th.Numbers.Rect(Plus), image.Rect(0, 128, 16, 160)},
+		Case{"alpha", th.Alpha.Rect(strings.IndexRune(th.Letters, 's')), image.Rect(64, 112, 80, 128)},
+	}
+	for _, cs := range cases {
+		if cs.r != cs.want {
+			t.Error(cs.t, cs.r, cs.want)
+		}
+	}
+	if names := ThemeNames(); len(names) == 0 || names[0] != ClassicTheme {
+		t.Error("names", names)
+	}
+}
+
+func testAtlas(w, h int) []byte {
+	var buf bytes.Buffer
+	png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h)))
+	return buf.Bytes()
+}
+
+func TestLoadTheme(t *testing.T) {
+	const double = `{"name": "Double", "image": "atlas.png", "scale": 2,
+		"stones": {"x": 0, "y": 0, "w": 32, "h": 32, "cols": 8},
+		"numbers": {"x": 0, "y": 64, "w": 32, "h": 64, "cols": 8, "rows": [0, 1, 3]},
+		"alpha": {"x": 0, "y": 192, "w": 32, "h": 32, "cols": 8},
+		"letters": "cutnaligko@es"}`
+	type Case struct {
+		t        string
+		manifest string
+		atlas    []byte
+		err      string
+	}
+	cases := []Case{
+		Case{"double", double, testAtlas(256, 320), ""},
+		Case{"small atlas", double, testAtlas(256, 300), "outside the 256x300 image"},
+		Case{"wrong size", strings.Replace(double, `"scale": 2`, `"scale": 1`, 1), testAtlas(256, 320), "must be 16x16 at scale 1"},
+		Case{"bad scale", strings.Replace(double, `"scale": 2`, `"scale": 0`, 1), testAtlas(256, 320), "scale must be positive"},
+		Case{"few rows", strings.Replace(double, `[0, 1, 3]`, `[0, 1]`, 1), testAtlas(256, 320), "16 sprites, 17 needed"},
+		Case{"no image", double, nil, "atlas.png"},
+		Case{"broken manifest", "{", testAtlas(256, 320), "theme.json"},
+	}
+	for _, cs := range cases {
+		fsys := fstest.MapFS{"double/theme.json": &fstest.MapFile{Data: []byte(cs.manifest)}}
+		if cs.atlas != nil {
+			fsys["double/atlas.png"] = &fstest.MapFile{Data: cs.atlas}
+		}
+		th, err := LoadTheme(fsys, "double")
+		if cs.err == "" {
+			if err != nil {
+				t.Error(cs.t, err)
+				continue
+			}
+			defer UseTheme(CurrentTheme)
+			UseTheme(th)
+			if SpriteScale != 0.5 {
+				t.Error(cs.t, "scale", SpriteScale)
+			}
+			if w, _ := StoneImages[Red].Size(); w != 32 {
+				t.Error(cs.t, "stone", w)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), cs.err) {
+			t.Error(cs.t, err)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+// Lesson is a staged board played by one forced cut of Len stones into column X.
+type Lesson struct {
+	Prompt string
+	Stage  *Stage
+	X, Len int
+}
+
+var Lessons []Lesson = []Lesson{
+	{
+		"Cut 1 stone in column 3 to line up 3.",
+		&Stage{
+			Name:  "Tutorial 1",
+			Pick:  []Color{Red, Blue, Blue},
+			Goal:  Goal{Kind: MakeChain, N: 1},
+			Cells: []StageCell{{1, 14, Red}, {2, 14, Red}},
+		},
+		3, 1,
+	},
+	{
+		"Point higher for more: cut 2 in column 2.",
+		&Stage{
+			Name:  "Tutorial 2",
+			Pick:  []Color{Red, Red, Blue},
+			Goal:  Goal{Kind: MakeChain, N: 1},
+			Cells: []StageCell{{2, 14, Red}},
+		},
+		2, 2,
+	},
+	{
+		"Diagonals count. Cut 1 in column 3.",
+		&Stage{
+			Name: "Tutorial 3",
+			Pick: []Color{Green, Blue, Red},
+			Goal: Goal{Kind: MakeChain, N: 1},
+			Cells: []StageCell{
+				{1, 14, Green}, {2, 13, Green}, {2, 14, Blue},
+				{3, 13, Red}, {3, 14, Blue},
+			},
+		},
+		3, 1,
+	},
+	{
+		"Falling stones chain. Cut 2 in column 3.",
+		&Stage{
+			Name: "Tutorial 4",
+			Pick: []Color{Red, Blue, Green},
+			Goal: Goal{Kind: MakeChain, N: 2},
+			Cells: []StageCell{
+				{1, 14, Red}, {2, 14, Red}, {4, 14, Blue}, {5, 14, Blue},
+			},
+		},
+		3, 2,
+	},
+}
+
+// Guided modes may refuse a cut.
+type Guided interface {
+	AllowCut(g *Game) bool
+}
+
+// TutorialMode plays the lessons in order, going on to the next one on clear.
+type TutorialMode struct {
+	*PuzzleMode
+	Lesson int
+}
+
+func NewTutorial(lesson int) *TutorialMode {
+	return &TutorialMode{NewPuzzleMode(Lessons[lesson].Stage), lesson}
+}
+
+func (m *TutorialMode) Start(g *Game) {
+	m.PuzzleMode.Start(g)
+	g.Back = func(g *Game) {
+		if g.Step == Clear && m.Lesson+1 < len(Lessons) {
+			g.Start(NewTutorial(m.Lesson + 1))
+			return
+		}
+		if g.Step == Clear {
+			g.OpenMenu(MainMenu())
+			return
+		}
+		// try the lesson again
+		g.Start(NewTutorial(m.Lesson))
+	}
+}
+
+func (m *TutorialMode) AllowCut(g *Game) bool {
+	l := Lessons[m.Lesson]
+	return g.PickX == l.X && g.PickLen == l.Len
+}
+
+// Expired keeps lessons going, they end by the cut they teach.
+func (m *TutorialMode) Expired(g *Game) (Step, bool) {
+	return Move, false
+}
+
+func (m *TutorialMode) Draw(g *Game, r *ebiten.Image) {
+	l := Lessons[m.Lesson]
+	switch g.Step {
+	case Move:
+		// blink where the pick should be held
+		if g.Ticks/8%2 == 0 && !m.AllowCut(g) {
+			bottom := g.PickBottom(l.X)
+			for i := 0; i < l.Len; i++ {
+				g.Board.RenderCursor(r, l.X, bottom-i)
+			}
+		}
+		DrawPrompt(r, g.Board, T(l.Prompt))
+	case WaitErase:
+		// show the lines just matched
+		for _, mg := range g.Match.Groups {
+			for _, p := range mg.Cells {
+				g.Board.RenderCursor(r, p.x, p.y)
+			}
+		}
+	case Clear:
+		DrawPrompt(r, g.Board, T("Well done! Tap to go on."))
+	case GameOver:
+		DrawPrompt(r, g.Board, T("Tap to try again."))
+	}
+}
+
+// PromptLines fit above the board, clear of the stones.
+const PromptLines = 2
+
+// DrawPrompt draws text wrapped to the frame, just above b.
+func DrawPrompt(r *ebiten.Image, b *Board, text string) {
+	lines := DefaultFont.Wrap(text, ScreenWidth-4)
+	DrawText(r, strings.Join(lines, "\n"), Viewport.Left()+2, b.OriginY-len(lines)*FontHeight-1)
+}
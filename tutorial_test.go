@@ -0,0 +1,104 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestTutorialLessons(t *testing.T) {
+	defer func(s Storage) { DefaultStorage = s }(DefaultStorage)
+	DefaultStorage = MemoryStorage{}
+
+	for i, l := range Lessons {
+		g := NewGame()
+		g.Start(NewTutorial(i))
+		// a wrong cut is refused
+		x := l.X%(BoardWidth-2) + 1
+		g.AdjustPick(x, g.PickBottom(x)-l.Len+1)
+		g.FixPick()
+		if g.Step != Move {
+			t.Error(l.Stage.Name, "wrong cut placed")
+		}
+		g.AdjustPick(l.X, g.PickBottom(l.X)-l.Len)
+		g.FixPick()
+		if g.Step != Move {
+			t.Error(l.Stage.Name, "too long cut placed")
+		}
+		g.AdjustPick(l.X, g.PickBottom(l.X)-l.Len+1)
+		g.FixPick()
+		for i := 0; i < 100 && g.Step != Move && g.Step != Clear && g.Step != GameOver; i++ {
+			g.Update()
+		}
+		if g.Step != Clear {
+			t.Error(l.Stage.Name, "not cleared", g.Step)
+			continue
+		}
+		if u := LoadUnlocked(); len(u) > 0 {
+			t.Error(l.Stage.Name, "unlocked", u)
+		}
+		if s := LoadLifetime(); s.Games > 0 {
+			t.Error(l.Stage.Name, "counted in stats")
+		}
+		g.Back(g)
+		if i+1 < len(Lessons) {
+			if m, ok := g.Mode.(*TutorialMode); !ok || m.Lesson != i+1 {
+				t.Error(l.Stage.Name, "next lesson not started", g.Mode)
+			}
+		} else if g.Step != Select {
+			t.Error(l.Stage.Name, "menu not opened", g.Step)
+		}
+	}
+}
+
+func TestMenuMoveCursor(t *testing.T) {
+	m := &Menu{Items: []MenuItem{
+		{"a", nil},
+		{"b", func(g *Game) {}},
+		{"c", nil},
+		{"d", func(g *Game) {}},
+	}}
+	type Case struct {
+		t      string
+		d      int
+		cursor int
+	}
+	cases := []Case{
+		Case{"down", 1, 1},
+		Case{"down skips", 1, 3},
+		Case{"down wraps", 1, 1},
+		Case{"up wraps", -1, 3},
+	}
+	for _, cs := range cases {
+		m.MoveCursor(cs.d)
+		if m.Cursor != cs.cursor {
+			t.Error(cs.t, m.Cursor, cs.cursor)
+		}
+	}
+}
+
+func TestTutorialPrompts(t *testing.T) {
+	defer func(l Lang) { CurrentLang = l }(CurrentLang)
+	prompts := []string{"Well done! Tap to go on.", "Tap to try again."}
+	for _, l := range Lessons {
+		prompts = append(prompts, l.Prompt)
+	}
+	for _, lang := range Langs {
+		CurrentLang = lang
+		for _, p := range prompts {
+			lines := DefaultFont.Wrap(T(p), ScreenWidth-4)
+			if len(lines) > PromptLines {
+				t.Error(lang, p, "lines", len(lines))
+			}
+			for _, line := range lines {
+				if w := DefaultFont.LineWidth(line); w > ScreenWidth-4 {
+					t.Error(lang, line, "width", w)
+				}
+			}
+		}
+	}
+	// above the board, below the top of the view
+	b := NewBoard()
+	b.Place()
+	if y := b.OriginY - PromptLines*FontHeight - 1; y < Viewport.Top() {
+		t.Error("prompt above the view", y)
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+)
+
+const (
+	ZenColors = 4
+	// rows cleared from the top when a column reaches it
+	ZenClearRows = 5
+)
+
+// Zen is played without jammers and without game over, until the player quits.
+type Zen struct {
+	Endless
+}
+
+func (Zen) Name() string {
+	return "Zen"
+}
+
+func (Zen) Next(g *Game) *Stone {
+	if len(g.Buffer) == 0 {
+		colors := []Color{Red, Blue, Green, Yellow, Pink, Orange}[:ZenColors]
+		g.Rand.Shuffle(len(colors), func(i, j int) {
+			colors[i], colors[j] = colors[j], colors[i]
+		})
+		g.Buffer = colors
+	}
+	var c Color
+	c, g.Buffer = g.Buffer[0], g.Buffer[1:]
+	return &Stone{Color: c}
+}
+
+func (Zen) Level(g *Game) int {
+	return ZenColors
+}
+
+func (Zen) TurnEnd(g *Game) Step {
+	if g.Board.ReachedTop() {
+		g.Board.ClearRows(1, ZenClearRows)
+	}
+	g.ReservePick()
+	return Move
+}
+
+// Expired ends the game when quit is pressed.
+func (Zen) Expired(g *Game) (Step, bool) {
+	return Clear, QuitPressed(g)
+}
+
+func (Zen) Draw(g *Game, r *ebiten.Image) {
+	DrawQuit(g, r)
+}
+
+// ReachedTop reports whether a stone is on the top row of the playfield.
+func (b *Board) ReachedTop() bool {
+	for x := 1; x < BoardWidth-1; x++ {
+		if b.HeightAt(x) <= 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// ClearRows removes the stones of rows top to bottom inside the walls.
+func (b *Board) ClearRows(top, bottom int) {
+	for y := top; y <= bottom; y++ {
+		for x := 1; x < BoardWidth-1; x++ {
+			if c, ok := b.At(x, y); ok {
+				*c = nil
+			}
+		}
+	}
+	b.Settle()
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestZenTurnEnd(t *testing.T) {
+	type Case struct {
+		t       string
+		heights []int
+		turn    int
+		stones  int
+	}
+	// heights are the number of stones stacked in columns from x = 1
+	cases := []Case{
+		Case{"low", []int{3, 2}, 1, 5},
+		Case{"no jammer", []int{3}, JammerTurn, 3},
+		Case{"reached top", []int{BoardHeight - 2, 4}, 1, BoardHeight - 2 - ZenClearRows + 4},
+		Case{"full", []int{14, 14, 14, 14, 14, 14}, 1, 6 * (BoardHeight - 2 - ZenClearRows)},
+	}
+	for _, cs := range cases {
+		g := NewGame()
+		g.Start(Zen{})
+		colors := []Color{Red, Blue}
+		for i, h := range cs.heights {
+			for y := BoardHeight - 2; y > BoardHeight-2-h; y-- {
+				if c, ok := g.Board.At(i+1, y); ok {
+					*c = &Stone{Color: colors[(i+y)%2]}
+				}
+			}
+		}
+		g.Turn = cs.turn
+		if step := g.Mode.TurnEnd(g); step != Move {
+			t.Error(cs.t, "step", step)
+		}
+		if n := g.Board.Remaining(); n != cs.stones {
+			t.Error(cs.t, "stones", n, cs.stones)
+		}
+		if g.Board.Count(Jammer) != 0 {
+			t.Error(cs.t, "jammer dropped")
+		}
+	}
+}
+
+func TestZenColors(t *testing.T) {
+	g := NewGame()
+	g.Start(Zen{})
+	g.Turn = 100
+	seen := map[Color]bool{}
+	for i := 0; i < 100; i++ {
+		seen[g.Next().Color] = true
+	}
+	if len(seen) != ZenColors {
+		t.Error("colors", seen)
+	}
+}